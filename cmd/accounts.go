@@ -11,5 +11,6 @@ var accountsCmd = &cobra.Command{
 }
 
 func init() {
-	// rootCmd.AddCommand(accountsCmd)
+	rootCmd.AddCommand(accountsCmd)
+	addRecordCommands(accountsCmd, "Account")
 }
@@ -1,19 +1,134 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/darrenparkinson/sfcli/pkg/ledger"
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
-// used for both bulk insert and bulk upsert
+// used for bulk insert, upsert and update
 var file string
 var sobject string
 var crlfLineEnding bool
+var skipValidation bool
+var validateOnly bool
+var mapFile string
+var fileFormat string
+var sheet string
+var delimiter string
+var encoding string
+var snapshotRecords bool
+var syncThreshold int
 
 var bulkCmd = &cobra.Command{
 	Use:   "bulk",
 	Short: "Bulk API V2 Commands",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// resume may be finishing one of the jobs we'd otherwise abort
+		if cmd != bulkResumeCmd {
+			abortOrphans(context.Background(), viper.GetDuration("orphanTimeout"))
+		}
+	},
 }
 
 func init() {
 	rootCmd.AddCommand(bulkCmd)
+
+	bulkCmd.PersistentFlags().Duration("orphan-timeout", 24*time.Hour, "Abort jobs created by sfcli that have been left open for longer than this, 0 to disable")
+	viper.BindPFlag("orphanTimeout", bulkCmd.PersistentFlags().Lookup("orphan-timeout"))
+}
+
+// recordJob adds the job to the local ledger, warning rather than failing if that isn't possible
+func recordJob(e ledger.Entry) {
+	if app.ledger == nil {
+		return
+	}
+	if err := app.ledger.Record(e); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to record job %s in ledger: %s\n", e.ID, err)
+	}
+}
+
+// newEntry returns a ledger entry for a job we have just created in the current org
+func newEntry(job *salesforce.JobInfo) ledger.Entry {
+	now := time.Now()
+	e := ledger.Entry{
+		ID:         job.ID,
+		Org:        app.config.BaseURL,
+		Username:   app.config.Username,
+		Object:     job.Object,
+		Operation:  job.Operation,
+		ExternalID: job.ExternalIDFieldName,
+		State:      job.State,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if u, err := user.Current(); err == nil {
+		e.User = u.Username
+	}
+	return e
+}
+
+// refreshJob updates the state and counts of a job in the ledger, if it is one of ours
+func refreshJob(job *salesforce.JobInfo) {
+	if app.ledger == nil {
+		return
+	}
+	e, err := app.ledger.Get(job.ID)
+	if errors.Is(err, ledger.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to read ledger: %s\n", err)
+		return
+	}
+	if e.State == job.State && e.RecordsProcessed == job.NumberRecordsProcessed && e.RecordsFailed == job.NumberRecordsFailed {
+		return
+	}
+	e.State = job.State
+	e.RecordsProcessed = job.NumberRecordsProcessed
+	e.RecordsFailed = job.NumberRecordsFailed
+	e.UpdatedAt = time.Now()
+	recordJob(*e)
+}
+
+// abortOrphans aborts the jobs in the current org that sfcli created but didn't finish loading,
+// e.g. because it was interrupted, and that have been left open for longer than the timeout
+func abortOrphans(ctx context.Context, timeout time.Duration) {
+	if app.ledger == nil || timeout <= 0 {
+		return
+	}
+	entries, err := app.ledger.Entries()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to read ledger: %s\n", err)
+		return
+	}
+	for _, e := range entries {
+		if e.Org != app.config.BaseURL || e.State != "Open" || time.Since(e.UpdatedAt) < timeout {
+			continue
+		}
+		if e.Stage != ledger.StageCreated && e.Stage != ledger.StageUploaded {
+			continue
+		}
+		job, err := app.sc.BulkService.GetJob(ctx, salesforce.BulkTypeIngest, e.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to check open job %s: %s\n", e.ID, err)
+			continue
+		}
+		if job.State == "Open" {
+			if job, err = app.sc.BulkService.CancelJob(ctx, salesforce.BulkTypeIngest, e.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: unable to abort open job %s: %s\n", e.ID, err)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "Aborted job %s, left open since %s\n", e.ID, e.UpdatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		refreshJob(job)
+	}
 }
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/darrenparkinson/sfcli/pkg/convert"
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var bulkCopyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy the results of a query in another org into this org",
+	Run:   bulkCopy,
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkCopyCmd)
+
+	bulkCopyCmd.Flags().String("source-config", "", "Config file for the org to copy from")
+	viper.BindPFlag("bulkCopySourceConfig", bulkCopyCmd.Flags().Lookup("source-config"))
+
+	bulkCopyCmd.Flags().StringP("query", "q", "", "SOQL query to run in the source org")
+	viper.BindPFlag("bulkCopyQuery", bulkCopyCmd.Flags().Lookup("query"))
+
+	bulkCopyCmd.Flags().StringVarP(&sobject, "sobject", "s", "", "Type of SObject to load in this org, e.g. Account, Contact, Opportunity")
+	viper.BindPFlag("sobject", bulkCopyCmd.Flags().Lookup("sobject"))
+
+	bulkCopyCmd.Flags().StringP("operation", "o", "insert", "Operation for the load: insert, upsert or update")
+	viper.BindPFlag("bulkCopyOperation", bulkCopyCmd.Flags().Lookup("operation"))
+
+	bulkCopyCmd.Flags().StringP("external", "e", "", "External ID Field, required for upsert")
+	viper.BindPFlag("bulkCopyExternal", bulkCopyCmd.Flags().Lookup("external"))
+
+	bulkCopyCmd.Flags().StringVar(&mapFile, "map", "", "YAML file mapping the columns of the query results to fields")
+	viper.BindPFlag("map", bulkCopyCmd.Flags().Lookup("map"))
+
+	bulkCopyCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip validating the records against the object's fields as they are uploaded")
+	viper.BindPFlag("skipValidation", bulkCopyCmd.Flags().Lookup("skip-validation"))
+}
+
+func bulkCopy(cmd *cobra.Command, args []string) {
+	sourceConfig := viper.GetString("bulkCopySourceConfig")
+	if sourceConfig == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: source config is required")
+		os.Exit(1)
+	}
+	query := viper.GetString("bulkCopyQuery")
+	if query == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: query is required")
+		os.Exit(1)
+	}
+	object := viper.GetString("sobject")
+	if object == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: sobject type is required")
+		os.Exit(1)
+	}
+	operation := viper.GetString("bulkCopyOperation")
+	external := viper.GetString("bulkCopyExternal")
+	if operation == "upsert" && external == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: external id is required")
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(sourceConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem reading source config: %s\n", err)
+		os.Exit(1)
+	}
+	source, err := newClient(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: Problem initialising source salesforce client")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	if query, err = expandFields(ctx, source, query); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	job, err := source.BulkService.CreateJob(ctx, salesforce.BulkRequest{Operation: "query", Query: query, ContentType: "CSV"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem creating query job: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Query Job Created in %s: %s (%s)\n", cfg.BaseURL, job.ID, job.State)
+	job, err = source.BulkService.WaitForJob(ctx, salesforce.BulkTypeQuery, job.ID, 5*time.Second)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem waiting for query job: %s\n", err)
+		os.Exit(1)
+	}
+	if job.State != "JobComplete" {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Query job %s %s: %s\n", job.ID, job.State, job.ErrorMessage)
+		os.Exit(1)
+	}
+	fmt.Printf("Query complete, copying %d records...\n", job.NumberRecordsProcessed)
+
+	// the results are streamed straight into the upload, so nothing is written to disk
+	results := source.BulkService.QueryResultsReader(ctx, job.ID)
+	opts := ingestOptions{
+		object:         object,
+		operation:      operation,
+		externalID:     external,
+		format:         string(convert.FormatCSV),
+		delimiter:      "comma",
+		encoding:       convert.EncodingUTF8,
+		mapFile:        viper.GetString("map"),
+		skipValidation: viper.GetBool("skipValidation"),
+		source:         fmt.Sprintf("%s: %s", cfg.BaseURL, query),
+		input:          results,
+	}
+	res, err := runIngest(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Job: %s; Status: %s\n", res.ID, res.State)
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/darrenparkinson/sfcli/pkg/salesforce"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -19,14 +18,38 @@ var bulkInsertCmd = &cobra.Command{
 func init() {
 	bulkCmd.AddCommand(bulkInsertCmd)
 
-	bulkInsertCmd.Flags().StringVarP(&file, "file", "f", "", "CSV File")
+	bulkInsertCmd.Flags().StringVarP(&file, "file", "f", "", "File to load, or - to read from stdin")
 	viper.BindPFlag("file", bulkInsertCmd.Flags().Lookup("file"))
 
 	bulkInsertCmd.Flags().StringVarP(&sobject, "sobject", "s", "", "Type of Object for Insert, e.g. Account, Contact, Opportunity")
 	viper.BindPFlag("sobject", bulkInsertCmd.Flags().Lookup("sobject"))
 
-	bulkInsertCmd.Flags().BoolVarP(&crlfLineEnding, "crlf", "c", false, "Specify CRLF Line Ending (default is LF)")
+	bulkInsertCmd.Flags().StringVar(&fileFormat, "format", "", "Format of the file: csv, json, ndjson or xlsx (default is based on the file extension)")
+	viper.BindPFlag("format", bulkInsertCmd.Flags().Lookup("format"))
+
+	bulkInsertCmd.Flags().StringVar(&sheet, "sheet", "", "Name or number of the sheet to load from a workbook (default is the first sheet)")
+	viper.BindPFlag("sheet", bulkInsertCmd.Flags().Lookup("sheet"))
+
+	bulkInsertCmd.Flags().BoolVarP(&crlfLineEnding, "crlf", "c", false, "Specify CRLF Line Ending (default is detected from the file)")
 	viper.BindPFlag("crlf", bulkInsertCmd.Flags().Lookup("crlf"))
+
+	bulkInsertCmd.Flags().StringVar(&delimiter, "delimiter", "", "Column delimiter: comma, tab, semicolon, pipe, caret or backquote (default is detected from the file)")
+	viper.BindPFlag("delimiter", bulkInsertCmd.Flags().Lookup("delimiter"))
+
+	bulkInsertCmd.Flags().StringVar(&encoding, "encoding", "auto", "Encoding of the file: auto, utf-8, utf-16, windows-1252 or iso-8859-1")
+	viper.BindPFlag("encoding", bulkInsertCmd.Flags().Lookup("encoding"))
+
+	bulkInsertCmd.Flags().StringVar(&mapFile, "map", "", "YAML file mapping the columns of the file to fields")
+	viper.BindPFlag("map", bulkInsertCmd.Flags().Lookup("map"))
+
+	bulkInsertCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip validating the file against the object's fields before creating the job")
+	viper.BindPFlag("skipValidation", bulkInsertCmd.Flags().Lookup("skip-validation"))
+
+	bulkInsertCmd.Flags().BoolVar(&validateOnly, "validate-only", false, "Validate the file against the object's fields without creating a job")
+	viper.BindPFlag("validateOnly", bulkInsertCmd.Flags().Lookup("validate-only"))
+
+	bulkInsertCmd.Flags().IntVar(&syncThreshold, "sync-threshold", 0, "Load files of up to this many records with the synchronous sObject collections API rather than a bulk job")
+	viper.BindPFlag("syncThreshold", bulkInsertCmd.Flags().Lookup("sync-threshold"))
 }
 
 func bulkInsert(cmd *cobra.Command, args []string) {
@@ -42,46 +65,27 @@ func bulkInsert(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	crlf := viper.GetBool("crlf")
-
-	// check file exists
-	file, err := os.Open(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
-		os.Exit(1)
-	}
-	defer file.Close()
-
-	// create a job
-	br := salesforce.BulkRequest{
-		Object:      object,
-		ContentType: "CSV", // TODO: Make this a parameter?
-		Operation:   "insert",
-	}
-	if crlf {
-		br.LineEnding = "CRLF"
-	}
-	job, err := app.sc.BulkService.CreateJob(context.Background(), br)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
-		os.Exit(1)
+	opts := ingestOptions{
+		file:           filename,
+		object:         object,
+		operation:      "insert",
+		format:         viper.GetString("format"),
+		sheet:          viper.GetString("sheet"),
+		crlf:           viper.GetBool("crlf"),
+		delimiter:      viper.GetString("delimiter"),
+		encoding:       viper.GetString("encoding"),
+		mapFile:        viper.GetString("map"),
+		skipValidation: viper.GetBool("skipValidation"),
+		validateOnly:   viper.GetBool("validateOnly"),
+		syncThreshold:  viper.GetInt("syncThreshold"),
 	}
-	fmt.Printf("Job Created for insert: %s (%s)\n", job.ID, job.State)
-
-	// upload the csv
-	err = app.sc.BulkService.UploadCSV(context.Background(), job.ID, file)
+	res, err := runIngest(context.Background(), opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
 		os.Exit(1)
 	}
-	fmt.Println("File content uploaded, starting job...", job.ID)
-
-	// begin the job
-	res, err := app.sc.BulkService.ProcessJob(context.Background(), salesforce.BulkTypeIngest, job.ID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
-		os.Exit(1)
+	if res != nil {
+		fmt.Printf("Job: %s; Status: %s\n", res.ID, res.State)
 	}
-	fmt.Printf("Job: %s; Status: %s\n", res.ID, res.State)
 
 }
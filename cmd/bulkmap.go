@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var bulkMapTemplateCmd = &cobra.Command{
+	Use:   "map-template",
+	Short: "Generate a starter mapping file for an object",
+	Run:   bulkMapTemplate,
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkMapTemplateCmd)
+
+	bulkMapTemplateCmd.Flags().StringVarP(&sobject, "sobject", "s", "", "Type of SObject for the mapping, e.g. Account, Contact, Opportunity")
+	viper.BindPFlag("sobject", bulkMapTemplateCmd.Flags().Lookup("sobject"))
+
+	bulkMapTemplateCmd.Flags().StringP("output", "o", "", "File to write the mapping to (default is stdout)")
+	viper.BindPFlag("mapTemplateOutput", bulkMapTemplateCmd.Flags().Lookup("output"))
+}
+
+func bulkMapTemplate(cmd *cobra.Command, args []string) {
+	object := viper.GetString("sobject")
+	if object == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: sobject type is required")
+		os.Exit(1)
+	}
+	dr, err := app.sc.Describe(context.Background(), object)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	// required fields are mapped, everything else that can be written is commented out
+	var required, optional bytes.Buffer
+	for _, f := range dr.Fields {
+		if !f.Createable && !f.Updateable {
+			continue
+		}
+		if f.RequiredOnCreate() {
+			fmt.Fprintf(&required, "  - target: %s # %s (%s, required)\n", f.Name, f.Label, f.Type)
+			fmt.Fprintf(&required, "    source: %s\n", f.Name)
+			continue
+		}
+		fmt.Fprintf(&optional, "  # - target: %s # %s (%s)\n", f.Name, f.Label, f.Type)
+		fmt.Fprintf(&optional, "  #   source: %s\n", f.Name)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Mapping for %s generated by sfcli\n", dr.Name)
+	fmt.Fprintln(&b, "# Set each source to the name of the column in your file.  Uncomment any other fields you need.")
+	fmt.Fprintln(&b, "# Fields can also use concat, value, transform (trim, upper, lower), lookup, date and datetime.")
+	fmt.Fprintln(&b, "fields:")
+	b.Write(required.Bytes())
+	b.Write(optional.Bytes())
+	fmt.Fprintln(&b, "# Columns listed here are removed, all other columns are passed through unchanged.")
+	fmt.Fprintln(&b, "drop: []")
+
+	if output := viper.GetString("mapTemplateOutput"); output != "" {
+		if err := ioutil.WriteFile(output, b.Bytes(), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Print(b.String())
+}
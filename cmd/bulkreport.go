@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var bulkReportCmd = &cobra.Command{
+	Use:   "report <jobId>",
+	Short: "Download the successful, failed and unprocessed records for a job",
+	Args:  cobra.ExactArgs(1),
+	Run:   bulkReport,
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkReportCmd)
+
+	bulkReportCmd.Flags().StringP("dir", "d", "", "Directory for the report (default is the job id)")
+	viper.BindPFlag("bulkReportDir", bulkReportCmd.Flags().Lookup("dir"))
+}
+
+// jobReport is the summary written alongside the results of a job
+type jobReport struct {
+	Job                   *salesforce.JobInfo `json:"job"`
+	SuccessfulRecords     int                 `json:"successfulRecords"`
+	FailedRecords         int                 `json:"failedRecords"`
+	UnprocessedRecords    int                 `json:"unprocessedRecords"`
+	SuccessfulResultsFile string              `json:"successfulResultsFile"`
+	FailedResultsFile     string              `json:"failedResultsFile"`
+	UnprocessedFile       string              `json:"unprocessedRecordsFile"`
+	GeneratedAt           time.Time           `json:"generatedAt"`
+}
+
+func bulkReport(cmd *cobra.Command, args []string) {
+	id := args[0]
+	dir := viper.GetString("bulkReportDir")
+	if dir == "" {
+		dir = id
+	}
+
+	job, err := ingestJob(context.Background(), id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting job status for ingest job: %s\n", err)
+		os.Exit(1)
+	}
+	refreshJob(job)
+	delimiter, err := salesforce.Delimiter(job.ColumnDelimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	report := jobReport{
+		Job:                   job,
+		SuccessfulRecords:     job.NumberRecordsProcessed - job.NumberRecordsFailed,
+		FailedRecords:         job.NumberRecordsFailed,
+		SuccessfulResultsFile: filepath.Join(dir, "successful.csv"),
+		FailedResultsFile:     filepath.Join(dir, "failed.csv"),
+		UnprocessedFile:       filepath.Join(dir, "unprocessed.csv"),
+		GeneratedAt:           time.Now(),
+	}
+
+	success, err := jobResults(context.Background(), id, resultsSuccessful)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting success results: %s\n", err)
+		os.Exit(1)
+	}
+	_, err = writeResults(report.SuccessfulResultsFile, success, delimiter)
+	success.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	failed, err := jobResults(context.Background(), id, resultsFailed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting error results: %s\n", err)
+		os.Exit(1)
+	}
+	_, err = writeResults(report.FailedResultsFile, failed, delimiter)
+	failed.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	unprocessed, err := jobResults(context.Background(), id, resultsUnprocessed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting unprocessed records: %s\n", err)
+		os.Exit(1)
+	}
+	report.UnprocessedRecords, err = writeResults(report.UnprocessedFile, unprocessed, delimiter)
+	unprocessed.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "summary.json"), b, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	printJobStatus(job)
+	fmt.Println()
+	fmt.Printf("Report written to %s: %d successful, %d failed, %d unprocessed\n", dir, report.SuccessfulRecords, report.FailedRecords, report.UnprocessedRecords)
+}
+
+// writeResults copies the CSV results in r to the named file and returns the number of records, excluding the header
+func writeResults(name string, r io.Reader, delimiter rune) (int, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	cr := csv.NewReader(io.TeeReader(r, f))
+	cr.Comma = delimiter
+	cr.FieldsPerRecord = -1
+	rows := -1
+	for {
+		_, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		rows++
+	}
+	if rows < 0 {
+		rows = 0
+	}
+	return rows, f.Close()
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/darrenparkinson/sfcli/pkg/ledger"
+	"github.com/darrenparkinson/sfcli/pkg/mapping"
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+)
+
+var bulkResumeCmd = &cobra.Command{
+	Use:   "resume <jobId>",
+	Short: "Finish an ingest job that was interrupted before it was started",
+	Args:  cobra.ExactArgs(1),
+	Run:   bulkResume,
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkResumeCmd)
+}
+
+func bulkResume(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	e := historyEntry(args[0])
+	if e.Org != app.config.BaseURL {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Job %s was created in %s\n", e.ID, e.Org)
+		os.Exit(1)
+	}
+	job, err := app.sc.BulkService.GetJob(ctx, salesforce.BulkTypeIngest, e.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting job status for ingest job: %s\n", err)
+		os.Exit(1)
+	}
+	if job.State != "Open" {
+		refreshJob(job)
+		fmt.Printf("Job %s has already been started, nothing to resume; Status: %s\n", job.ID, job.State)
+		return
+	}
+
+	// anything before the upload completed has to be uploaded again
+	if e.Stage != ledger.StageUploaded {
+		if err := resumeUpload(ctx, e, job); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		checkpoint(e, ledger.StageUploaded, job.State)
+		fmt.Println("File content uploaded, starting job...", job.ID)
+	}
+
+	res, err := app.sc.BulkService.ProcessJob(ctx, salesforce.BulkTypeIngest, job.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	checkpoint(e, ledger.StageStarted, res.State)
+	fmt.Printf("Job: %s; Status: %s\n", res.ID, res.State)
+}
+
+// resumeUpload uploads the file recorded in the ledger to the job again, using the
+// delimiter and line ending the job was created with
+func resumeUpload(ctx context.Context, e *ledger.Entry, job *salesforce.JobInfo) error {
+	if !e.IsFile() {
+		return fmt.Errorf("job %s was not loaded from a file (%s) so can't be uploaded again, it will be aborted once it has been open for longer than the orphan timeout", e.ID, e.Source)
+	}
+	if e.Checksum != "" {
+		sum, err := ledger.Checksum(e.Source)
+		if err != nil {
+			return err
+		}
+		if sum != e.Checksum {
+			return fmt.Errorf("%s has changed since job %s was created", e.Source, e.ID)
+		}
+	}
+	opts := entryOptions(e)
+	opts.columnDelimiter, opts.lineEnding = job.ColumnDelimiter, job.LineEnding
+	var m *mapping.Mapping
+	if opts.mapFile != "" {
+		var err error
+		if m, err = mapping.Load(opts.mapFile); err != nil {
+			return err
+		}
+	}
+	src, err := openSource(opts, m)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fmt.Printf("Uploading %s to job %s...\n", e.Source, e.ID)
+	return app.sc.BulkService.UploadCSV(ctx, job.ID, src)
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+var bulkRetryCmd = &cobra.Command{
+	Use:   "retry <jobId>",
+	Short: "Retry the failed records of an ingest job",
+	Args:  cobra.ExactArgs(1),
+	Run:   bulkRetry,
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkRetryCmd)
+
+	bulkRetryCmd.Flags().String("fix", "", "YAML file of fixes to apply to the failed records before retrying")
+	viper.BindPFlag("bulkRetryFix", bulkRetryCmd.Flags().Lookup("fix"))
+}
+
+// retryFixes is the format of the file provided with the --fix option, e.g.
+//
+//	fixes:
+//	  - error: INVALID_EMAIL_ADDRESS
+//	    column: Email
+//	    set: ""
+//	  - column: Country
+//	    replace:
+//	      UK: United Kingdom
+//	  - error: DUPLICATE_VALUE
+//	    skip: true
+type retryFixes struct {
+	Fixes []retryFix `yaml:"fixes"`
+}
+
+// retryFix applies to rows whose sf__Error contains Error, or all rows if Error is empty
+type retryFix struct {
+	Error   string            `yaml:"error"`
+	Column  string            `yaml:"column"`
+	Set     *string           `yaml:"set"`
+	Replace map[string]string `yaml:"replace"`
+	Skip    bool              `yaml:"skip"`
+}
+
+func bulkRetry(cmd *cobra.Command, args []string) {
+	id := args[0]
+
+	var fixes retryFixes
+	if fixFile := viper.GetString("bulkRetryFix"); fixFile != "" {
+		b, err := ioutil.ReadFile(fixFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		if err := yaml.UnmarshalStrict(b, &fixes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: Problem reading fix file: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	job, err := ingestJob(context.Background(), id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting job status for ingest job: %s\n", err)
+		os.Exit(1)
+	}
+	failed, err := jobResults(context.Background(), id, resultsFailed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting error results: %s\n", err)
+		os.Exit(1)
+	}
+	defer failed.Close()
+	delimiter, err := salesforce.Delimiter(job.ColumnDelimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	var payload bytes.Buffer
+	errorCounts, rows, err := prepareRetry(failed, delimiter, fixes.Fixes, &payload)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem preparing failed records: %s\n", err)
+		os.Exit(1)
+	}
+	printRetryErrors(errorCounts)
+	if rows == 0 {
+		fmt.Println("No failed records to retry for job", id)
+		return
+	}
+
+	// create a job matching the original
+	br := salesforce.BulkRequest{
+		Object:              job.Object,
+		ContentType:         "CSV",
+		Operation:           job.Operation,
+		ExternalIDFieldName: job.ExternalIDFieldName,
+	}
+	fmt.Printf("Retrying %d records of %s...\n", rows, job.ID)
+	res, err := submitJob(context.Background(), br, &payload, job.ID, fmt.Sprintf("failed records of %s", job.ID))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Job: %s; Status: %s\n", res.ID, res.State)
+}
+
+// prepareRetry reads the failed results, removes the sf__ columns and applies any fixes
+// before writing the records to w as a comma delimited CSV.  It returns the number of
+// failed records per error message and the number of records written.
+func prepareRetry(failed io.Reader, delimiter rune, fixes []retryFix, w io.Writer) (map[string]int, int, error) {
+	r := csv.NewReader(failed)
+	r.Comma = delimiter
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	errorIdx := -1
+	var keep []int
+	columns := make(map[string]int)
+	var outHeader []string
+	for i, h := range header {
+		switch {
+		case h == "sf__Error":
+			errorIdx = i
+		case strings.HasPrefix(h, "sf__"):
+		default:
+			keep = append(keep, i)
+			columns[h] = i
+			outHeader = append(outHeader, h)
+		}
+	}
+	if errorIdx < 0 {
+		return nil, 0, fmt.Errorf("sf__Error column not found in failed results")
+	}
+	for _, f := range fixes {
+		if _, ok := columns[f.Column]; !ok && !f.Skip {
+			return nil, 0, fmt.Errorf("fix refers to unknown column: %s", f.Column)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(outHeader); err != nil {
+		return nil, 0, err
+	}
+	counts := make(map[string]int)
+	rows := 0
+records:
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		sfErr := record[errorIdx]
+		counts[sfErr]++
+		for _, f := range fixes {
+			if !strings.Contains(sfErr, f.Error) {
+				continue
+			}
+			if f.Skip {
+				continue records
+			}
+			i := columns[f.Column]
+			if v, ok := f.Replace[record[i]]; ok {
+				record[i] = v
+			}
+			if f.Set != nil {
+				record[i] = *f.Set
+			}
+		}
+		out := make([]string, 0, len(keep))
+		for _, i := range keep {
+			out = append(out, record[i])
+		}
+		if err := cw.Write(out); err != nil {
+			return nil, 0, err
+		}
+		rows++
+	}
+	cw.Flush()
+	return counts, rows, cw.Error()
+}
+
+func printRetryErrors(counts map[string]int) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	errs := make([]string, 0, len(counts))
+	for e := range counts {
+		errs = append(errs, e)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		if counts[errs[i]] == counts[errs[j]] {
+			return errs[i] < errs[j]
+		}
+		return counts[errs[i]] > counts[errs[j]]
+	})
+	fmt.Println()
+	blue.Println("FAILED RECORDS")
+	tbl := table.New("Records", "Error")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, e := range errs {
+		tbl.AddRow(counts[e], e)
+	}
+	tbl.Print()
+	fmt.Println()
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+)
+
+var bulkRollbackCmd = &cobra.Command{
+	Use:   "rollback <jobId>",
+	Short: "Undo an ingest job by restoring its snapshot and deleting the records it created",
+	Args:  cobra.ExactArgs(1),
+	Run:   bulkRollback,
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkRollbackCmd)
+}
+
+func bulkRollback(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	e := historyEntry(args[0])
+	if e.Org != app.config.BaseURL {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Job %s was created in %s\n", e.ID, e.Org)
+		os.Exit(1)
+	}
+	job, err := ingestJob(ctx, e.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting job status for ingest job: %s\n", err)
+		os.Exit(1)
+	}
+	refreshJob(job)
+	switch job.State {
+	case "JobComplete", "Failed", "Aborted":
+	default:
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Job %s is %s, wait for it to finish before rolling it back\n", job.ID, job.State)
+		os.Exit(1)
+	}
+
+	results, err := jobResults(ctx, job.ID, resultsSuccessful)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting success results: %s\n", err)
+		os.Exit(1)
+	}
+	defer results.Close()
+	delimiter, err := salesforce.Delimiter(job.ColumnDelimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	created, updated, err := splitCreated(results, delimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem reading success results: %s\n", err)
+		os.Exit(1)
+	}
+
+	var restore bytes.Buffer
+	restored := 0
+	if len(updated) > 0 {
+		if e.Snapshot == "" {
+			fmt.Fprintf(os.Stderr, "Warning: no snapshot was taken for job %s, so %d updated records can't be restored\n", job.ID, len(updated))
+		} else if restored, err = prepareRestore(e.Snapshot, updated, &restore); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: Problem reading snapshot: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if restored == 0 && len(created) == 0 {
+		fmt.Println("Nothing to roll back for job", job.ID)
+		return
+	}
+	fmt.Printf("Rolling back job %s: restoring %d records and deleting %d records\n", job.ID, restored, len(created))
+
+	if restored > 0 {
+		br := salesforce.BulkRequest{Object: job.Object, ContentType: "CSV", Operation: "update"}
+		res, err := submitJob(ctx, br, &restore, job.ID, fmt.Sprintf("rollback of %s", job.ID))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Restore Job: %s; Status: %s\n", res.ID, res.State)
+	}
+	if len(created) > 0 {
+		var ids bytes.Buffer
+		w := csv.NewWriter(&ids)
+		w.Write([]string{"Id"})
+		for _, id := range created {
+			w.Write([]string{id})
+		}
+		w.Flush()
+		br := salesforce.BulkRequest{Object: job.Object, ContentType: "CSV", Operation: "delete"}
+		res, err := submitJob(ctx, br, &ids, job.ID, fmt.Sprintf("rollback of %s", job.ID))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Delete Job: %s; Status: %s\n", res.ID, res.State)
+	}
+}
+
+// splitCreated reads the successful results of a job and returns the ids of the records it created
+// and a set of the ids of the records it updated, keyed by their 15 character id
+func splitCreated(results io.Reader, delimiter rune) ([]string, map[string]bool, error) {
+	r := csv.NewReader(results)
+	r.Comma = delimiter
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	idIdx, createdIdx := -1, -1
+	for i, h := range header {
+		switch h {
+		case "sf__Id":
+			idIdx = i
+		case "sf__Created":
+			createdIdx = i
+		}
+	}
+	if idIdx < 0 || createdIdx < 0 {
+		return nil, nil, fmt.Errorf("sf__Id and sf__Created columns not found in successful results")
+	}
+	var created []string
+	updated := make(map[string]bool)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		id := record[idIdx]
+		if strings.EqualFold(record[createdIdx], "true") {
+			created = append(created, id)
+		} else if len(id) >= 15 {
+			updated[id[:15]] = true
+		}
+	}
+	return created, updated, nil
+}
+
+// prepareRestore writes the records in the snapshot that were updated as a CSV for an update job,
+// setting empty values to #N/A so that fields that were empty are cleared again
+func prepareRestore(snapshot string, updated map[string]bool, w io.Writer) (int, error) {
+	f, err := os.Open(snapshot)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return 0, err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+	rows := 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if id := record[0]; len(id) < 15 || !updated[id[:15]] {
+			continue
+		}
+		for i := 1; i < len(record); i++ {
+			if record[i] == "" {
+				record[i] = "#N/A"
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return 0, err
+		}
+		rows++
+	}
+	cw.Flush()
+	return rows, cw.Error()
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/darrenparkinson/sfcli/pkg/salesforce"
@@ -27,12 +28,18 @@ var bulkErrorResultsCmd = &cobra.Command{
 	Short: "Download the error results",
 	Run:   bulkErrorResults,
 }
+var bulkUnprocessedRecordsCmd = &cobra.Command{
+	Use:   "unprocessed",
+	Short: "Download the unprocessed records",
+	Run:   bulkUnprocessedRecords,
+}
 
 func init() {
 	bulkCmd.AddCommand(bulkStatusCmd)
 
 	bulkStatusCmd.AddCommand(bulkSuccessResultsCmd)
 	bulkStatusCmd.AddCommand(bulkErrorResultsCmd)
+	bulkStatusCmd.AddCommand(bulkUnprocessedRecordsCmd)
 
 	bulkStatusCmd.Flags().StringP("id", "i", "", "Job ID")
 	viper.BindPFlag("bulkStatusID", bulkStatusCmd.Flags().Lookup("id"))
@@ -40,6 +47,8 @@ func init() {
 	viper.BindPFlag("bulkSuccessID", bulkSuccessResultsCmd.Flags().Lookup("id"))
 	bulkErrorResultsCmd.Flags().StringP("id", "i", "", "Job ID")
 	viper.BindPFlag("bulkErrorID", bulkErrorResultsCmd.Flags().Lookup("id"))
+	bulkUnprocessedRecordsCmd.Flags().StringP("id", "i", "", "Job ID")
+	viper.BindPFlag("bulkUnprocessedID", bulkUnprocessedRecordsCmd.Flags().Lookup("id"))
 
 }
 
@@ -49,12 +58,16 @@ func bulkSuccessResults(cmd *cobra.Command, args []string) {
 		fmt.Fprintln(os.Stderr, "Error executing CLI: ID is required")
 		os.Exit(1)
 	}
-	res, err := app.sc.BulkService.GetSuccessfulResults(context.Background(), salesforce.BulkTypeIngest, id)
+	rc, err := jobResults(context.Background(), id, resultsSuccessful)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting success results: %s\n", err)
 		os.Exit(1)
 	}
-	fmt.Println(res)
+	defer rc.Close()
+	if _, err := io.Copy(os.Stdout, rc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting success results: %s\n", err)
+		os.Exit(1)
+	}
 }
 func bulkErrorResults(cmd *cobra.Command, args []string) {
 	id := viper.GetString("bulkErrorID")
@@ -62,12 +75,33 @@ func bulkErrorResults(cmd *cobra.Command, args []string) {
 		fmt.Fprintln(os.Stderr, "Error executing CLI: ID is required")
 		os.Exit(1)
 	}
-	res, err := app.sc.BulkService.GetFailedResults(context.Background(), salesforce.BulkTypeIngest, id)
+	rc, err := jobResults(context.Background(), id, resultsFailed)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting error results: %s\n", err)
 		os.Exit(1)
 	}
-	fmt.Println(res)
+	defer rc.Close()
+	if _, err := io.Copy(os.Stdout, rc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting error results: %s\n", err)
+		os.Exit(1)
+	}
+}
+func bulkUnprocessedRecords(cmd *cobra.Command, args []string) {
+	id := viper.GetString("bulkUnprocessedID")
+	if id == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: ID is required")
+		os.Exit(1)
+	}
+	rc, err := jobResults(context.Background(), id, resultsUnprocessed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting unprocessed records: %s\n", err)
+		os.Exit(1)
+	}
+	defer rc.Close()
+	if _, err := io.Copy(os.Stdout, rc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting unprocessed records: %s\n", err)
+		os.Exit(1)
+	}
 }
 
 func bulkStatus(cmd *cobra.Command, args []string) {
@@ -77,11 +111,12 @@ func bulkStatus(cmd *cobra.Command, args []string) {
 		fmt.Fprintln(os.Stderr, "Error executing CLI: ID is required")
 		os.Exit(1)
 	}
-	bs, err := app.sc.BulkService.GetJob(context.Background(), salesforce.BulkTypeIngest, id)
+	bs, err := ingestJob(context.Background(), id)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem getting job status for ingest job: %s\n", err)
 		os.Exit(1)
 	}
+	refreshJob(bs)
 	printJobStatus(bs)
 }
 
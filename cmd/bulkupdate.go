@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var bulkUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Bulk Update a CSV File",
+	Run:   bulkUpdate,
+}
+
+func init() {
+	bulkCmd.AddCommand(bulkUpdateCmd)
+
+	bulkUpdateCmd.Flags().StringVarP(&file, "file", "f", "", "File to load, or - to read from stdin")
+	viper.BindPFlag("file", bulkUpdateCmd.Flags().Lookup("file"))
+
+	bulkUpdateCmd.Flags().StringVarP(&sobject, "sobject", "s", "", "Type of SObject for Update, e.g. Account, Contact, Opportunity")
+	viper.BindPFlag("sobject", bulkUpdateCmd.Flags().Lookup("sobject"))
+
+	bulkUpdateCmd.Flags().StringVar(&fileFormat, "format", "", "Format of the file: csv, json, ndjson or xlsx (default is based on the file extension)")
+	viper.BindPFlag("format", bulkUpdateCmd.Flags().Lookup("format"))
+
+	bulkUpdateCmd.Flags().StringVar(&sheet, "sheet", "", "Name or number of the sheet to load from a workbook (default is the first sheet)")
+	viper.BindPFlag("sheet", bulkUpdateCmd.Flags().Lookup("sheet"))
+
+	bulkUpdateCmd.Flags().BoolVarP(&crlfLineEnding, "crlf", "c", false, "Specify CRLF Line Ending (default is detected from the file)")
+	viper.BindPFlag("crlf", bulkUpdateCmd.Flags().Lookup("crlf"))
+
+	bulkUpdateCmd.Flags().StringVar(&delimiter, "delimiter", "", "Column delimiter: comma, tab, semicolon, pipe, caret or backquote (default is detected from the file)")
+	viper.BindPFlag("delimiter", bulkUpdateCmd.Flags().Lookup("delimiter"))
+
+	bulkUpdateCmd.Flags().StringVar(&encoding, "encoding", "auto", "Encoding of the file: auto, utf-8, utf-16, windows-1252 or iso-8859-1")
+	viper.BindPFlag("encoding", bulkUpdateCmd.Flags().Lookup("encoding"))
+
+	bulkUpdateCmd.Flags().StringVar(&mapFile, "map", "", "YAML file mapping the columns of the file to fields")
+	viper.BindPFlag("map", bulkUpdateCmd.Flags().Lookup("map"))
+
+	bulkUpdateCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip validating the file against the object's fields before creating the job")
+	viper.BindPFlag("skipValidation", bulkUpdateCmd.Flags().Lookup("skip-validation"))
+
+	bulkUpdateCmd.Flags().BoolVar(&validateOnly, "validate-only", false, "Validate the file against the object's fields without creating a job")
+	viper.BindPFlag("validateOnly", bulkUpdateCmd.Flags().Lookup("validate-only"))
+
+	bulkUpdateCmd.Flags().IntVar(&syncThreshold, "sync-threshold", 0, "Load files of up to this many records with the synchronous sObject collections API rather than a bulk job")
+	viper.BindPFlag("syncThreshold", bulkUpdateCmd.Flags().Lookup("sync-threshold"))
+
+	bulkUpdateCmd.Flags().BoolVar(&snapshotRecords, "snapshot", false, "Save the current values of the records being updated so the load can be rolled back")
+	viper.BindPFlag("snapshot", bulkUpdateCmd.Flags().Lookup("snapshot"))
+}
+
+func bulkUpdate(cmd *cobra.Command, args []string) {
+
+	filename := viper.GetString("file")
+	if filename == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: file is required")
+		os.Exit(1)
+	}
+	object := viper.GetString("sobject")
+	if object == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: sobject type is required")
+		os.Exit(1)
+	}
+
+	opts := ingestOptions{
+		file:           filename,
+		object:         object,
+		operation:      "update",
+		format:         viper.GetString("format"),
+		sheet:          viper.GetString("sheet"),
+		crlf:           viper.GetBool("crlf"),
+		delimiter:      viper.GetString("delimiter"),
+		encoding:       viper.GetString("encoding"),
+		mapFile:        viper.GetString("map"),
+		skipValidation: viper.GetBool("skipValidation"),
+		validateOnly:   viper.GetBool("validateOnly"),
+		syncThreshold:  viper.GetInt("syncThreshold"),
+		snapshot:       viper.GetBool("snapshot"),
+	}
+	res, err := runIngest(context.Background(), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if res != nil {
+		fmt.Printf("Job: %s; Status: %s\n", res.ID, res.State)
+	}
+
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/darrenparkinson/sfcli/pkg/salesforce"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -19,7 +18,7 @@ var bulkUpsertCmd = &cobra.Command{
 func init() {
 	bulkCmd.AddCommand(bulkUpsertCmd)
 
-	bulkUpsertCmd.Flags().StringVarP(&file, "file", "f", "", "CSV File")
+	bulkUpsertCmd.Flags().StringVarP(&file, "file", "f", "", "File to load, or - to read from stdin")
 	viper.BindPFlag("file", bulkUpsertCmd.Flags().Lookup("file"))
 
 	bulkUpsertCmd.Flags().StringVarP(&sobject, "sobject", "s", "", "Type of SObject for Insert, e.g. Account, Contact, Opportunity")
@@ -28,8 +27,41 @@ func init() {
 	bulkUpsertCmd.Flags().StringP("external", "e", "", "External ID Field")
 	viper.BindPFlag("external", bulkUpsertCmd.Flags().Lookup("external"))
 
-	bulkUpsertCmd.Flags().BoolVarP(&crlfLineEnding, "crlf", "c", false, "Specify CRLF Line Ending (default is LF)")
+	bulkUpsertCmd.Flags().StringVar(&fileFormat, "format", "", "Format of the file: csv, json, ndjson or xlsx (default is based on the file extension)")
+	viper.BindPFlag("format", bulkUpsertCmd.Flags().Lookup("format"))
+
+	bulkUpsertCmd.Flags().StringVar(&sheet, "sheet", "", "Name or number of the sheet to load from a workbook (default is the first sheet)")
+	viper.BindPFlag("sheet", bulkUpsertCmd.Flags().Lookup("sheet"))
+
+	bulkUpsertCmd.Flags().BoolVarP(&crlfLineEnding, "crlf", "c", false, "Specify CRLF Line Ending (default is detected from the file)")
 	viper.BindPFlag("crlf", bulkUpsertCmd.Flags().Lookup("crlf"))
+
+	bulkUpsertCmd.Flags().StringVar(&delimiter, "delimiter", "", "Column delimiter: comma, tab, semicolon, pipe, caret or backquote (default is detected from the file)")
+	viper.BindPFlag("delimiter", bulkUpsertCmd.Flags().Lookup("delimiter"))
+
+	bulkUpsertCmd.Flags().StringVar(&encoding, "encoding", "auto", "Encoding of the file: auto, utf-8, utf-16, windows-1252 or iso-8859-1")
+	viper.BindPFlag("encoding", bulkUpsertCmd.Flags().Lookup("encoding"))
+
+	bulkUpsertCmd.Flags().StringVar(&mapFile, "map", "", "YAML file mapping the columns of the file to fields")
+	viper.BindPFlag("map", bulkUpsertCmd.Flags().Lookup("map"))
+
+	bulkUpsertCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip validating the file against the object's fields before creating the job")
+	viper.BindPFlag("skipValidation", bulkUpsertCmd.Flags().Lookup("skip-validation"))
+
+	bulkUpsertCmd.Flags().BoolVar(&validateOnly, "validate-only", false, "Validate the file against the object's fields without creating a job")
+	viper.BindPFlag("validateOnly", bulkUpsertCmd.Flags().Lookup("validate-only"))
+
+	bulkUpsertCmd.Flags().IntVar(&syncThreshold, "sync-threshold", 0, "Load files of up to this many records with the synchronous sObject collections API rather than a bulk job")
+	viper.BindPFlag("syncThreshold", bulkUpsertCmd.Flags().Lookup("sync-threshold"))
+
+	bulkUpsertCmd.Flags().BoolVar(&snapshotRecords, "snapshot", false, "Save the current values of the records being updated so the load can be rolled back")
+	viper.BindPFlag("snapshot", bulkUpsertCmd.Flags().Lookup("snapshot"))
+
+	bulkUpsertCmd.Flags().Bool("dry-run", false, "Compare the file with the existing records without creating a job")
+	viper.BindPFlag("bulkUpsertDryRun", bulkUpsertCmd.Flags().Lookup("dry-run"))
+
+	bulkUpsertCmd.Flags().String("diff-output", "diff.csv", "File to write the differences found by --dry-run to, as CSV or, with a .json extension, JSON")
+	viper.BindPFlag("bulkUpsertDiffOutput", bulkUpsertCmd.Flags().Lookup("diff-output"))
 }
 
 func bulkUpsert(cmd *cobra.Command, args []string) {
@@ -50,47 +82,36 @@ func bulkUpsert(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	crlf := viper.GetBool("crlf")
-
-	// check file exists
-	file, err := os.Open(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
-		os.Exit(1)
-	}
-	defer file.Close()
-
-	// create a job
-	br := salesforce.BulkRequest{
-		Object:              object,
-		ContentType:         "CSV", // TODO: Make this a parameter?
-		Operation:           "upsert",
-		ExternalIDFieldName: external,
+	opts := ingestOptions{
+		file:           filename,
+		object:         object,
+		operation:      "upsert",
+		externalID:     external,
+		format:         viper.GetString("format"),
+		sheet:          viper.GetString("sheet"),
+		crlf:           viper.GetBool("crlf"),
+		delimiter:      viper.GetString("delimiter"),
+		encoding:       viper.GetString("encoding"),
+		mapFile:        viper.GetString("map"),
+		skipValidation: viper.GetBool("skipValidation"),
+		validateOnly:   viper.GetBool("validateOnly"),
+		syncThreshold:  viper.GetInt("syncThreshold"),
+		snapshot:       viper.GetBool("snapshot"),
 	}
-	if crlf {
-		br.LineEnding = "CRLF"
-	}
-	job, err := app.sc.BulkService.CreateJob(context.Background(), br)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
-		os.Exit(1)
+	if viper.GetBool("bulkUpsertDryRun") {
+		if err := dryRunUpsert(context.Background(), opts, viper.GetString("bulkUpsertDiffOutput")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-	fmt.Printf("Job Created for upsert: %s (%s)\n", job.ID, job.State)
-
-	// upload the csv
-	err = app.sc.BulkService.UploadCSV(context.Background(), job.ID, file)
+	res, err := runIngest(context.Background(), opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
 		os.Exit(1)
 	}
-	fmt.Println("File content uploaded, starting job...", job.ID)
-
-	// begin the job
-	res, err := app.sc.BulkService.ProcessJob(context.Background(), salesforce.BulkTypeIngest, job.ID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
-		os.Exit(1)
+	if res != nil {
+		fmt.Printf("Job: %s; Status: %s\n", res.ID, res.State)
 	}
-	fmt.Printf("Started: %s; Status: %s\n", res.ID, res.State)
 
 }
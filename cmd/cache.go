@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of object descriptions",
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:         "clear",
+	Short:       "Remove every cached object description, for all orgs",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{offlineAnnotation: "always"},
+	Run:         cacheClear,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+func cacheClear(cmd *cobra.Command, args []string) {
+	dir, err := describeCacheDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if err := salesforce.NewFileCache(dir).Clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Cleared the describe cache in", dir)
+}
@@ -10,5 +10,6 @@ var contactsCmd = &cobra.Command{
 }
 
 func init() {
-	// rootCmd.AddCommand(contactsCmd)
+	rootCmd.AddCommand(contactsCmd)
+	addRecordCommands(contactsCmd, "Contact")
 }
@@ -1,20 +1,29 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"strings"
+	"unicode"
 
 	"github.com/darrenparkinson/sfcli/pkg/salesforce"
-	"github.com/fatih/color"
-	"github.com/rodaine/table"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
 )
 
 //TODO: Abstract this to enable description of anything...
-//TODO: Enable the user to specify what fields they want, or default to some...
+
+// defaultDescribeFields are the field attributes shown when --fields isn't given
+var defaultDescribeFields = []string{"name", "label", "type", "length", "unique", "updateable", "idLookup", "relationshipName", "referenceTo"}
+
+// describeFormats are the output formats of describe, which adds yaml to the formats for rows
+var describeFormats = []string{outputTable, outputMarkdown, outputCSV, outputJSON, "yaml"}
 
 var describeCmd = &cobra.Command{
 	Use:   "describe",
@@ -52,6 +61,30 @@ func init() {
 
 	describeCmd.Flags().StringP("object", "o", "", "Object to describe")
 	viper.BindPFlag("object", describeCmd.Flags().Lookup("object"))
+
+	describeCmd.PersistentFlags().StringSlice("fields", defaultDescribeFields, "Field attributes to show, e.g. name,label,type,picklistValues, or all")
+	viper.BindPFlag("describeFields", describeCmd.PersistentFlags().Lookup("fields"))
+
+	describeCmd.PersistentFlags().Bool("custom", false, "Only show custom fields")
+	viper.BindPFlag("describeCustom", describeCmd.PersistentFlags().Lookup("custom"))
+
+	describeCmd.PersistentFlags().Bool("updateable", false, "Only show fields that can be updated")
+	viper.BindPFlag("describeUpdateable", describeCmd.PersistentFlags().Lookup("updateable"))
+
+	describeCmd.PersistentFlags().Bool("external-id", false, "Only show external ID fields")
+	viper.BindPFlag("describeExternalID", describeCmd.PersistentFlags().Lookup("external-id"))
+
+	describeCmd.PersistentFlags().StringSlice("type", nil, "Only show fields of these types, e.g. reference,picklist")
+	viper.BindPFlag("describeType", describeCmd.PersistentFlags().Lookup("type"))
+
+	describeCmd.PersistentFlags().Bool("picklists", false, "Also show the values of the picklist fields shown")
+	viper.BindPFlag("describePicklists", describeCmd.PersistentFlags().Lookup("picklists"))
+
+	describeCmd.PersistentFlags().Bool("children", false, "Also show the child relationships of the object")
+	viper.BindPFlag("describeChildren", describeCmd.PersistentFlags().Lookup("children"))
+
+	describeCmd.PersistentFlags().String("output", outputTable, "Output format: "+strings.Join(describeFormats, ", "))
+	viper.BindPFlag("describeOutput", describeCmd.PersistentFlags().Lookup("output"))
 }
 
 func accountDescribe(cmd *cobra.Command, args []string) {
@@ -101,28 +134,288 @@ func customDescribe(cmd *cobra.Command, args []string) {
 	printDescription(dr)
 }
 
+// printDescription prints the fields of the object, filtered and with the attributes chosen by the
+// flags, followed by its record types and, if asked for, picklist values and child relationships.
+// JSON and YAML combine these into a single document, while CSV only has the fields.
 func printDescription(dr *salesforce.DescribeResponse) {
-	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
-	columnFmt := color.New(color.FgYellow).SprintfFunc()
-	blue := color.New(color.FgHiBlue)
-	fmt.Println()
-	blue.Println(dr.Label, "Fields")
-	tblFields := table.New("Name", "Label", "Type", "Length", "Unique", "Updateable", "ID Lookup", "Relationship Name", "Reference To")
-	tblFields.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
-	for _, f := range dr.Fields {
-		rels := ""
-		if f.RelationshipName != "" {
-			rels = strings.Join(f.ReferenceTo, "|")
-		}
-		tblFields.AddRow(f.Name, f.Label, f.Type, f.Length, f.Unique, f.Updateable, f.IDLookup, f.RelationshipName, rels)
-	}
-	tblFields.Print()
-	fmt.Println()
-	blue.Println(dr.Label, "Record Types")
-	tblRecordTypes := table.New("ID", "Name", "Developer Name", "Available", "Active")
-	tblRecordTypes.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	if err := writeDescription(os.Stdout, dr, viper.GetString("describeOutput")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func writeDescription(w io.Writer, dr *salesforce.DescribeResponse, format string) error {
+	format = strings.ToLower(format)
+	valid := false
+	for _, f := range describeFormats {
+		valid = valid || f == format
+	}
+	if !valid {
+		return fmt.Errorf("unsupported output format %s, use one of %s", format, strings.Join(describeFormats, ", "))
+	}
+	attrs, err := describeAttributes(viper.GetStringSlice("describeFields"))
+	if err != nil {
+		return err
+	}
+	fields := filterFields(dr)
+	text := format != outputJSON && format != "yaml"
+
+	// the attributes are read by reflection so that every one in the describe can be chosen
+	rows := make([][]interface{}, len(fields))
+	for i, f := range fields {
+		v := reflect.ValueOf(dr.Fields[f])
+		row := make([]interface{}, len(attrs))
+		for j, a := range attrs {
+			row[j] = v.FieldByIndex(a.index).Interface()
+			if text {
+				row[j] = attributeText(a.name, row[j])
+			}
+		}
+		rows[i] = row
+	}
+
+	if !text {
+		doc := orderedMap{{"name", dr.Name}, {"label", dr.Label}}
+		fieldList := make([]orderedMap, len(rows))
+		for i, row := range rows {
+			for j, a := range attrs {
+				fieldList[i] = append(fieldList[i], orderedPair{a.name, row[j]})
+			}
+		}
+		doc = append(doc, orderedPair{"fields", fieldList})
+		if viper.GetBool("describePicklists") {
+			picklists := orderedMap{}
+			for _, f := range fields {
+				if values := dr.Fields[f].PicklistValues; len(values) > 0 {
+					picklists = append(picklists, orderedPair{dr.Fields[f].Name, values})
+				}
+			}
+			doc = append(doc, orderedPair{"picklistValues", picklists})
+		}
+		if viper.GetBool("describeChildren") {
+			doc = append(doc, orderedPair{"childRelationships", dr.ChildRelationships})
+		}
+		doc = append(doc, orderedPair{"recordTypeInfos", dr.RecordTypeInfos})
+		if format == "yaml" {
+			b, err := yaml.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	}
+
+	columns := make([]string, len(attrs))
+	for i, a := range attrs {
+		columns[i] = a.name
+		if format != outputCSV {
+			columns[i] = attributeHeading(a.name)
+		}
+	}
+	if err := writeRows(w, format, dr.Label+" Fields", columns, rows); err != nil {
+		return err
+	}
+	if format == outputCSV {
+		return nil
+	}
+
+	if viper.GetBool("describePicklists") {
+		var rows [][]interface{}
+		for _, f := range fields {
+			for _, p := range dr.Fields[f].PicklistValues {
+				rows = append(rows, []interface{}{dr.Fields[f].Name, p.Value, p.Label, p.Active, p.DefaultValue})
+			}
+		}
+		if err := writeRows(w, format, dr.Label+" Picklist Values", []string{"Field", "Value", "Label", "Active", "Default"}, rows); err != nil {
+			return err
+		}
+	}
+	if viper.GetBool("describeChildren") {
+		var rows [][]interface{}
+		for _, c := range dr.ChildRelationships {
+			rows = append(rows, []interface{}{c.RelationshipName, c.ChildSObject, c.Field, c.CascadeDelete, c.RestrictedDelete})
+		}
+		if err := writeRows(w, format, dr.Label+" Child Relationships", []string{"Relationship Name", "Child Object", "Field", "Cascade Delete", "Restricted Delete"}, rows); err != nil {
+			return err
+		}
+	}
+	rows = nil
 	for _, t := range dr.RecordTypeInfos {
-		tblRecordTypes.AddRow(t.RecordTypeID, t.Name, t.DeveloperName, t.Available, t.Active)
+		rows = append(rows, []interface{}{t.RecordTypeID, t.Name, t.DeveloperName, t.Available, t.Active})
+	}
+	return writeRows(w, format, dr.Label+" Record Types", []string{"ID", "Name", "Developer Name", "Available", "Active"}, rows)
+}
+
+// writeRows writes a section of the description with a row writer
+func writeRows(w io.Writer, format, title string, columns []string, rows [][]interface{}) error {
+	rw, err := newRowWriter(w, format, title, columns)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := rw.Write(row); err != nil {
+			return err
+		}
+	}
+	return rw.Flush()
+}
+
+// describeAttribute is a field attribute in the describe, by its JSON name
+type describeAttribute struct {
+	name  string
+	index []int
+}
+
+// describeAttributes returns the field attributes with the given JSON names, ignoring case, or all of them for "all"
+func describeAttributes(names []string) ([]describeAttribute, error) {
+	var dr salesforce.DescribeResponse
+	t := reflect.TypeOf(dr.Fields).Elem()
+	var all []describeAttribute
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			all = append(all, describeAttribute{name: name, index: t.Field(i).Index})
+		}
+	}
+	if len(names) == 1 && strings.EqualFold(names[0], "all") {
+		return all, nil
+	}
+	attrs := make([]describeAttribute, 0, len(names))
+	for _, n := range names {
+		found := false
+		for _, a := range all {
+			if strings.EqualFold(a.name, strings.TrimSpace(n)) {
+				attrs = append(attrs, a)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, len(all))
+			for i, a := range all {
+				available[i] = a.name
+			}
+			return nil, fmt.Errorf("unknown field attribute %s, use one of %s", n, strings.Join(available, ", "))
+		}
+	}
+	return attrs, nil
+}
+
+// filterFields returns the indexes of the fields that match the filter flags
+func filterFields(dr *salesforce.DescribeResponse) []int {
+	custom, updateable, externalID := viper.GetBool("describeCustom"), viper.GetBool("describeUpdateable"), viper.GetBool("describeExternalID")
+	types := viper.GetStringSlice("describeType")
+	var fields []int
+	for i, f := range dr.Fields {
+		if custom && !f.Custom || updateable && !f.Updateable || externalID && !f.ExternalID {
+			continue
+		}
+		if len(types) > 0 {
+			match := false
+			for _, t := range types {
+				match = match || strings.EqualFold(f.Type, strings.TrimSpace(t))
+			}
+			if !match {
+				continue
+			}
+		}
+		fields = append(fields, i)
+	}
+	return fields
+}
+
+// attributeText returns the value of an attribute for a table or CSV, listing the values of
+// picklists, the objects a reference refers to and the fields controlling a lookup filter
+func attributeText(name string, v interface{}) interface{} {
+	switch name {
+	case "picklistValues":
+		var values []string
+		for _, p := range v.([]salesforce.PicklistValue) {
+			values = append(values, p.Value)
+		}
+		return strings.Join(values, "|")
+	case "referenceTo":
+		return strings.Join(v.([]string), "|")
+	case "filteredLookupInfo":
+		if fl := v.(*salesforce.FilteredLookupInfo); fl != nil {
+			return strings.Join(fl.ControllingFields, "|")
+		}
+		return ""
+	case "relationshipOrder":
+		if order := v.(*int); order != nil {
+			return *order
+		}
+		return ""
+	}
+	return v
+}
+
+// attributeHeading turns the JSON name of an attribute into a heading, e.g. relationshipName
+// becomes Relationship Name
+func attributeHeading(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i == 0 {
+			r = unicode.ToUpper(r)
+		} else if unicode.IsUpper(r) && !unicode.IsUpper(rune(name[i-1])) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// orderedPair is an entry of an orderedMap
+type orderedPair struct {
+	Key   string
+	Value interface{}
+}
+
+// orderedMap is written as a JSON or YAML object with its keys in order
+type orderedMap []orderedPair
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(p.Key)
+		value, err := json.Marshal(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (m orderedMap) MarshalYAML() (interface{}, error) {
+	ms := make(yaml.MapSlice, len(m))
+	for i, p := range m {
+		v := p.Value
+		switch v.(type) {
+		case orderedMap, []orderedMap:
+		default:
+			// go through JSON so the keys of structs are their JSON names
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			v = nil
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, err
+			}
+		}
+		ms[i] = yaml.MapItem{Key: p.Key, Value: v}
 	}
-	tblRecordTypes.Print()
+	return ms, nil
 }
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+)
+
+// describeCacheDir returns the directory describe metadata is cached in, next to the ledger.
+// The client keeps each org and API version in its own subdirectory.
+func describeCacheDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sfcli", "cache"), nil
+}
+
+// cachedDescribe returns the describe metadata for the object.  With offline set, only the
+// cache is used, and nothing is cached when there are no credentials to say which org to use.
+func cachedDescribe(ctx context.Context, object string, offline bool) (*salesforce.DescribeResponse, error) {
+	if offline {
+		if app.sc == nil {
+			return nil, fmt.Errorf("no cached description of %s", object)
+		}
+		return app.sc.CachedDescribe(object)
+	}
+	return app.sc.Describe(ctx, object)
+}
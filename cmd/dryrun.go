@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/diff"
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// existingBatchSize is the most keys looked up by each query for existing records.  Long keys are
+// split into smaller queries to keep each one within maxQueryLength.
+const existingBatchSize = 200
+
+// maxQueryLength is the longest query sent, once escaped for the URL, which leaves room for the
+// rest of the URL within the 16,384 bytes salesforce accepts
+const maxQueryLength = 15000
+
+// queryExisting returns the fields of the records whose key field matches one of the keys, grouped
+// by key.  The values of each record are keyed by the names given in fields, and always include Id.
+// The keys are compared in lower case unless caseSensitive is set.
+func queryExisting(ctx context.Context, object, keyField string, fields, keys []string, caseSensitive bool) (map[string][]map[string]string, error) {
+	existing := make(map[string][]map[string]string)
+	if len(keys) == 0 {
+		return existing, nil
+	}
+	selected := []string{"Id"}
+	seen := map[string]bool{"id": true}
+	for _, f := range append([]string{keyField}, fields...) {
+		if !seen[strings.ToLower(f)] {
+			seen[strings.ToLower(f)] = true
+			selected = append(selected, f)
+		}
+	}
+	build := func(keys []string) (string, error) {
+		return salesforce.Select(selected...).From(object).Where(salesforce.In(keyField, keys)).Build()
+	}
+	query, err := build(keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(url.QueryEscape(query)) > maxQueryLength {
+		if len(keys) == 1 {
+			return nil, fmt.Errorf("the query for %s %s is too long", keyField, keys[0])
+		}
+		// keys that only differ in case can match the same record from both halves
+		half := len(keys) / 2
+		ids := make(map[string]bool)
+		for _, part := range [][]string{keys[:half], keys[half:]} {
+			found, err := queryExisting(ctx, object, keyField, fields, part, caseSensitive)
+			if err != nil {
+				return nil, err
+			}
+			for k, records := range found {
+				for _, r := range records {
+					if !ids[r["Id"]] {
+						ids[r["Id"]] = true
+						existing[k] = append(existing[k], r)
+					}
+				}
+			}
+		}
+		return existing, nil
+	}
+
+	// each batch of keys matches a bounded number of records, so they are queried directly
+	// rather than waiting for a bulk query job
+	it, err := app.sc.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("problem querying existing records: %w", err)
+	}
+	for it.Next() {
+		record := it.Record().Flatten()
+		values := make(map[string]string, len(selected))
+		for _, f := range selected {
+			v, _ := record.Get(f)
+			values[f] = formatValue(v)
+		}
+		key := values[keyField]
+		if !caseSensitive {
+			key = strings.ToLower(key)
+		}
+		existing[key] = append(existing[key], values)
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("problem querying existing records: %w", err)
+	}
+	return existing, nil
+}
+
+// diffWriter returns a writer for the diff based on the file extension, .json or .csv
+func diffWriter(w io.Writer, name string) diff.Writer {
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		return diff.NewJSONWriter(w)
+	}
+	return diff.NewCSVWriter(w)
+}
+
+// dryRunUpsert compares the file with the records in the org that have the same external ID and
+// writes the differences to output, without creating a job
+func dryRunUpsert(ctx context.Context, opts ingestOptions, output string) error {
+	m, err := prepareIngest(&opts)
+	if err != nil {
+		return err
+	}
+	if !opts.skipValidation && opts.input == nil {
+		if err := validateFile(ctx, opts, m); err != nil {
+			return err
+		}
+	}
+
+	dr, err := app.sc.Describe(ctx, opts.object)
+	if err != nil {
+		return fmt.Errorf("problem describing %s: %w", opts.object, err)
+	}
+	src, err := openSource(opts, m)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	r := csv.NewReader(src)
+	r.Comma = opts.comma()
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("problem reading %s: %w", opts.file, err)
+	}
+
+	// compare every column except polymorphic relationships, which can't be queried by name
+	keyIdx := -1
+	caseSensitive := false
+	var columns, types []string
+	var indexes []int
+	for i, h := range header {
+		if strings.EqualFold(h, opts.externalID) {
+			keyIdx = i
+			if f := dr.FieldByName(h); f != nil {
+				caseSensitive = f.CaseSensitive
+			}
+			continue
+		}
+		if strings.Contains(h, ":") {
+			fmt.Fprintf(os.Stderr, "Warning: %s can't be compared\n", h)
+			continue
+		}
+		fieldType := ""
+		if f := dr.FieldByName(h); f != nil {
+			fieldType = f.Type
+		}
+		columns = append(columns, h)
+		types = append(types, fieldType)
+		indexes = append(indexes, i)
+	}
+	if keyIdx < 0 {
+		return fmt.Errorf("external ID column %s is missing", opts.externalID)
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := diffWriter(out, output)
+
+	counts := make(map[diff.Status]int)
+	fieldCounts := make(map[string]int)
+	row := 0
+	compare := func(batch [][]string) error {
+		keys := make([]string, 0, len(batch))
+		for _, record := range batch {
+			if k := record[keyIdx]; k != "" {
+				keys = append(keys, k)
+			}
+		}
+		existing, err := queryExisting(ctx, opts.object, opts.externalID, columns, keys, caseSensitive)
+		if err != nil {
+			return err
+		}
+		for _, record := range batch {
+			row++
+			key := record[keyIdx]
+			match := key
+			if !caseSensitive {
+				match = strings.ToLower(key)
+			}
+			values := make([]string, len(indexes))
+			for i, idx := range indexes {
+				values[i] = record[idx]
+			}
+			res := diff.Compare(row, key, columns, types, values, existing[match])
+			counts[res.Status]++
+			for _, c := range res.Changes {
+				fieldCounts[c.Field]++
+			}
+			if err := w.Write(res); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var batch [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("problem reading %s: %w", opts.file, err)
+		}
+		batch = append(batch, record)
+		if len(batch) == existingBatchSize {
+			if err := compare(batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		if err := compare(batch); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	printDiffSummary(counts, fieldCounts)
+	fmt.Printf("Dry run of %d records complete, differences written to %s\n", row, output)
+	return nil
+}
+
+func printDiffSummary(counts map[diff.Status]int, fieldCounts map[string]int) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Println()
+	blue.Println("DRY RUN")
+	tbl := table.New("Status", "Records")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, s := range []diff.Status{diff.StatusNew, diff.StatusChanged, diff.StatusUnchanged, diff.StatusConflict} {
+		tbl.AddRow(s, counts[s])
+	}
+	tbl.Print()
+
+	if len(fieldCounts) > 0 {
+		fields := make([]string, 0, len(fieldCounts))
+		for f := range fieldCounts {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		fmt.Println()
+		blue.Println("CHANGED FIELDS")
+		tbl := table.New("Field", "Records")
+		tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+		for _, f := range fields {
+			tbl.AddRow(f, fieldCounts[f])
+		}
+		tbl.Print()
+	}
+	fmt.Println()
+}
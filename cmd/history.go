@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/darrenparkinson/sfcli/pkg/ledger"
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Commands for the bulk jobs created by sfcli",
+}
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the bulk jobs created by sfcli",
+	Run:   historyList,
+}
+var historyShowCmd = &cobra.Command{
+	Use:   "show <jobId>",
+	Short: "Show the details of a bulk job created by sfcli",
+	Args:  cobra.ExactArgs(1),
+	Run:   historyShow,
+}
+var historyRerunCmd = &cobra.Command{
+	Use:   "rerun <jobId>",
+	Short: "Load the file from a previous bulk job again with the same settings",
+	Args:  cobra.ExactArgs(1),
+	Run:   historyRerun,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyRerunCmd)
+
+	historyListCmd.Flags().BoolP("all", "a", false, "List jobs for all orgs, not just the current one")
+	viper.BindPFlag("historyAll", historyListCmd.Flags().Lookup("all"))
+
+	historyRerunCmd.Flags().Bool("skip-validation", false, "Skip validating the file against the object's fields before creating the job")
+	viper.BindPFlag("historySkipValidation", historyRerunCmd.Flags().Lookup("skip-validation"))
+}
+
+// historyLedger returns the ledger, exiting if there isn't one
+func historyLedger() *ledger.Ledger {
+	if app.ledger == nil {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: No user config directory available for the job history")
+		os.Exit(1)
+	}
+	return app.ledger
+}
+
+// historyEntry returns the ledger entry for the given job, exiting if it can't be found
+func historyEntry(id string) *ledger.Entry {
+	e, err := historyLedger().Get(id)
+	if errors.Is(err, ledger.ErrNotFound) {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Job %s was not created by sfcli\n", id)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem reading job history: %s\n", err)
+		os.Exit(1)
+	}
+	return e
+}
+
+func historyList(cmd *cobra.Command, args []string) {
+	entries, err := historyLedger().Entries()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem reading job history: %s\n", err)
+		os.Exit(1)
+	}
+	all := viper.GetBool("historyAll")
+	var jobs []ledger.Entry
+	for _, e := range entries {
+		if all || e.Org == app.config.BaseURL {
+			jobs = append(jobs, e)
+		}
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No jobs found in", app.ledger.Path())
+		return
+	}
+
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Println()
+	blue.Println("JOB HISTORY")
+	tbl := table.New("ID", "Created", "Operation", "Object", "Status", "Processed", "Failed", "Source")
+	if all {
+		tbl = table.New("ID", "Created", "Org", "Operation", "Object", "Status", "Processed", "Failed", "Source")
+	}
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, e := range jobs {
+		created := e.CreatedAt.Local().Format("2006-01-02 15:04")
+		if all {
+			tbl.AddRow(e.ID, created, e.Org, e.Operation, e.Object, e.State, e.RecordsProcessed, e.RecordsFailed, e.Source)
+		} else {
+			tbl.AddRow(e.ID, created, e.Operation, e.Object, e.State, e.RecordsProcessed, e.RecordsFailed, e.Source)
+		}
+	}
+	tbl.Print()
+	fmt.Println()
+}
+
+func historyShow(cmd *cobra.Command, args []string) {
+	e := historyEntry(args[0])
+
+	// bring the counts up to date if the job is in the org we're connected to.  Synchronous loads
+	// finished before they were recorded, so there's nothing to refresh.
+	if e.Org == app.config.BaseURL && !e.Sync {
+		job, err := app.sc.BulkService.GetJob(context.Background(), salesforce.BulkTypeIngest, e.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to get the current status of %s: %s\n", e.ID, err)
+		} else {
+			refreshJob(job)
+			e = historyEntry(e.ID)
+		}
+	}
+
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Println()
+	blue.Println("Job ID:", e.ID)
+	tbl := table.New("Field", "Value")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	tbl.AddRow("ID", e.ID)
+	if e.ParentID != "" {
+		tbl.AddRow("Parent", e.ParentID)
+	}
+	tbl.AddRow("Org", e.Org)
+	tbl.AddRow("Username", e.Username)
+	tbl.AddRow("User", e.User)
+	tbl.AddRow("Operation", e.Operation)
+	tbl.AddRow("Object", e.Object)
+	if e.ExternalID != "" {
+		tbl.AddRow("ExternalID", e.ExternalID)
+	}
+	tbl.AddRow("Source", e.Source)
+	if e.Checksum != "" {
+		tbl.AddRow("Checksum", e.Checksum)
+	}
+	if e.Format != "" {
+		tbl.AddRow("Format", e.Format)
+	}
+	if e.Sheet != "" {
+		tbl.AddRow("Sheet", e.Sheet)
+	}
+	if e.Delimiter != "" {
+		tbl.AddRow("Delimiter", e.Delimiter)
+	}
+	if e.Encoding != "" {
+		tbl.AddRow("Encoding", e.Encoding)
+	}
+	if e.Mapping != "" {
+		tbl.AddRow("Mapping", e.Mapping)
+	}
+	if e.Snapshot != "" {
+		tbl.AddRow("Snapshot", e.Snapshot)
+	}
+	tbl.AddRow("Status", e.State)
+	tbl.AddRow("RecordsProcessed", e.RecordsProcessed)
+	tbl.AddRow("RecordsFailed", e.RecordsFailed)
+	tbl.AddRow("Created", e.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	tbl.AddRow("Updated", e.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+	tbl.Print()
+
+	children, err := app.ledger.Children(e.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Problem reading job history: %s\n", err)
+		os.Exit(1)
+	}
+	if len(children) > 0 {
+		fmt.Println()
+		blue.Println("RELATED JOBS")
+		tbl := table.New("ID", "Created", "Status", "Processed", "Failed", "Source")
+		tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+		for _, c := range children {
+			tbl.AddRow(c.ID, c.CreatedAt.Local().Format("2006-01-02 15:04"), c.State, c.RecordsProcessed, c.RecordsFailed, c.Source)
+		}
+		tbl.Print()
+	}
+	fmt.Println()
+}
+
+func historyRerun(cmd *cobra.Command, args []string) {
+	e := historyEntry(args[0])
+	if !e.IsFile() {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: Job %s was not loaded from a file (%s) so can't be run again\n", e.ID, e.Source)
+		os.Exit(1)
+	}
+	if e.Org != app.config.BaseURL {
+		fmt.Fprintf(os.Stderr, "Warning: job %s was run against %s, it will now be loaded into %s\n", e.ID, e.Org, app.config.BaseURL)
+	}
+	if e.Checksum != "" {
+		sum, err := ledger.Checksum(e.Source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		if sum != e.Checksum {
+			fmt.Fprintf(os.Stderr, "Warning: %s has changed since job %s was run\n", e.Source, e.ID)
+		}
+	}
+
+	opts := entryOptions(e)
+	opts.skipValidation = viper.GetBool("historySkipValidation")
+	opts.parentID = e.ID
+	res, err := runIngest(context.Background(), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Job: %s; Status: %s\n", res.ID, res.State)
+}
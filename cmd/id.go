@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var idCmd = &cobra.Command{
+	Use:   "id <recordId>...",
+	Short: "Show the object of record ids, and convert them between their 15 and 18 character forms",
+	Example: `  sfcli id 0015e00000AbCdE
+  sfcli id 0015E00000ABCDEAAZ 0035e00000XyZaBAAV -o csv`,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{offlineAnnotation: "offline"},
+	Run:         showIDs,
+}
+
+func init() {
+	rootCmd.AddCommand(idCmd)
+
+	idCmd.Flags().Bool("offline", false, "Only convert the ids, without looking up their objects")
+	viper.BindPFlag("idOffline", idCmd.Flags().Lookup("offline"))
+
+	idCmd.Flags().StringP("output", "o", outputTable, "Output format: "+outputFormats)
+	viper.BindPFlag("idOutput", idCmd.Flags().Lookup("output"))
+}
+
+func showIDs(cmd *cobra.Command, args []string) {
+	type converted struct{ id15, id18 string }
+	ids := make([]converted, len(args))
+	for i, arg := range args {
+		id15, err := salesforce.ID15(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		id18, _ := salesforce.ID18(id15)
+		ids[i] = converted{id15, id18}
+	}
+
+	var dg *salesforce.DescribeGlobalResponse
+	if !viper.GetBool("idOffline") {
+		var err error
+		if dg, err = app.sc.DescribeGlobal(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	w, err := newRowWriter(os.Stdout, viper.GetString("idOutput"), "Record Ids", []string{"Id15", "Id18", "KeyPrefix", "Object", "Label"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	for _, c := range ids {
+		var object, label string
+		if dg != nil {
+			if o := dg.ObjectForID(c.id15); o != nil {
+				object, label = o.Name, o.Label
+			}
+		}
+		if err := w.Write([]interface{}{c.id15, c.id18, c.id15[:3], object, label}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,475 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/darrenparkinson/sfcli/pkg/convert"
+	"github.com/darrenparkinson/sfcli/pkg/ledger"
+	"github.com/darrenparkinson/sfcli/pkg/mapping"
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// errValidationFailed is returned when the pre-flight validation finds problems with the file
+var errValidationFailed = errors.New("validation failed, no job was created")
+
+// stdinFile is the file name used to read from stdin
+const stdinFile = "-"
+
+// ingestOptions holds the settings for a bulk ingest job, common to insert, upsert and update
+type ingestOptions struct {
+	file           string
+	object         string
+	operation      string
+	externalID     string
+	format         string // csv, json, ndjson or xlsx, detected from the file extension if empty
+	sheet          string // sheet name or number for workbooks
+	crlf           bool
+	delimiter      string // comma, tab, semicolon, pipe, caret or backquote, detected from the file if empty
+	encoding       string // encoding of csv files, detected from the file if empty
+	mapFile        string
+	skipValidation bool
+	validateOnly   bool
+	snapshot       bool // save the current values of the records being updated so the load can be rolled back
+	syncThreshold  int  // load up to this many records with the sObject collections API rather than a bulk job
+
+	// source describes where the data came from for the ledger when it is not a file
+	source string
+	// parentID is the job this load was created from, if any
+	parentID string
+
+	// input, if set, is a CSV stream that is read instead of the file.  It can only be read once,
+	// so it is validated as it is uploaded and the job is aborted if there are any problems.
+	input io.ReadCloser
+	// buffered holds the content of stdin for formats that must be read more than once
+	buffered []byte
+
+	// columnDelimiter and lineEnding are the bulk API settings for the data as it is uploaded
+	columnDelimiter string
+	lineEnding      string
+}
+
+// comma returns the delimiter character for the data as it is uploaded
+func (o ingestOptions) comma() rune {
+	r, _ := salesforce.Delimiter(o.columnDelimiter)
+	return r
+}
+
+// readCloser combines a reader with the closer of the underlying source
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// runIngest validates the file against the object's describe metadata, then creates the job,
+// uploads the file and starts the job.  It returns nil if only validation was requested or the
+// file was small enough to load synchronously.
+func runIngest(ctx context.Context, opts ingestOptions) (*salesforce.JobInfo, error) {
+	m, err := prepareIngest(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.skipValidation && (opts.input == nil || opts.validateOnly) {
+		if err := validateFile(ctx, opts, m); err != nil {
+			return nil, err
+		}
+		if opts.validateOnly {
+			return nil, nil
+		}
+	}
+
+	var snapshot string
+	if opts.snapshot {
+		var saved int
+		if snapshot, saved, err = takeSnapshot(ctx, opts, m); err != nil {
+			return nil, fmt.Errorf("problem taking snapshot: %w", err)
+		}
+		fmt.Printf("Saved the current values of %d %s records\n", saved, opts.object)
+	}
+
+	// check file exists
+	src, err := openSource(opts, m)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	// streams can only be read once, so validate them as they are uploaded
+	var validated func() error
+	if opts.input != nil && !opts.skipValidation {
+		if src, validated, err = validateStream(ctx, opts, src); err != nil {
+			return nil, err
+		}
+		defer src.Close()
+	}
+
+	// small loads finish sooner without the overhead of a job.  Snapshots are kept with the
+	// job they were taken for, so loads that need one always use a job.
+	if opts.syncThreshold > 0 && snapshot == "" && syncOperations[opts.operation] {
+		rows, rest := readRows(src, opts.comma(), opts.syncThreshold)
+		if rest == nil {
+			return nil, syncIngest(ctx, opts, rows, validated)
+		}
+		src = readCloser{rest, src}
+	}
+
+	// create a job
+	br := salesforce.BulkRequest{
+		Object:              opts.object,
+		ContentType:         "CSV", // other formats are converted to CSV as they are uploaded
+		Operation:           opts.operation,
+		ExternalIDFieldName: opts.externalID,
+		ColumnDelimiter:     opts.columnDelimiter,
+		LineEnding:          opts.lineEnding,
+	}
+	job, err := app.sc.BulkService.CreateJob(ctx, br)
+	if err != nil {
+		if snapshot != "" {
+			os.Remove(snapshot)
+		}
+		return nil, err
+	}
+	fmt.Printf("Job Created for %s: %s (%s)\n", opts.operation, job.ID, job.State)
+	entry := ledgerEntry(job, opts)
+	if snapshot != "" {
+		name := filepath.Join(app.ledger.SnapshotDir(), job.ID+".csv")
+		if err := os.Rename(snapshot, name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to rename snapshot %s: %s\n", snapshot, err)
+			name = snapshot
+		}
+		entry.Snapshot = name
+	}
+	checkpoint(&entry, ledger.StageCreated, job.State)
+
+	// upload the csv
+	err = app.sc.BulkService.UploadCSV(ctx, job.ID, src)
+	if err != nil {
+		return nil, err
+	}
+	if validated != nil {
+		if err := validated(); err != nil {
+			if _, cerr := app.sc.BulkService.CancelJob(ctx, salesforce.BulkTypeIngest, job.ID); cerr != nil {
+				return nil, fmt.Errorf("%s, and the job could not be aborted: %s", err, cerr)
+			}
+			return nil, fmt.Errorf("%s, job %s was aborted", err, job.ID)
+		}
+	}
+	checkpoint(&entry, ledger.StageUploaded, job.State)
+	fmt.Println("File content uploaded, starting job...", job.ID)
+
+	// begin the job
+	res, err := app.sc.BulkService.ProcessJob(ctx, salesforce.BulkTypeIngest, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint(&entry, ledger.StageStarted, res.State)
+	return res, nil
+}
+
+// prepareIngest works out the format and layout of the data and loads the mapping, if there is one
+func prepareIngest(opts *ingestOptions) (*mapping.Mapping, error) {
+	if opts.format == "" {
+		opts.format = string(convert.DetectFormat(opts.file))
+	}
+	format, err := convert.ParseFormat(opts.format)
+	if err != nil {
+		return nil, err
+	}
+	opts.format = string(format)
+
+	if opts.file == stdinFile && opts.input == nil {
+		if format == convert.FormatCSV {
+			opts.input = ioutil.NopCloser(os.Stdin)
+		} else if opts.buffered, err = ioutil.ReadAll(os.Stdin); err != nil {
+			return nil, err
+		}
+	}
+
+	// converted files are always written as comma delimited with LF line endings
+	opts.columnDelimiter, opts.lineEnding = "COMMA", "LF"
+	if format == convert.FormatCSV {
+		if err := detectLayout(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	var m *mapping.Mapping
+	if opts.mapFile != "" {
+		if m, err = mapping.Load(opts.mapFile); err != nil {
+			return nil, err
+		}
+		// the mapping writes LF line endings regardless of the source file
+		opts.lineEnding = "LF"
+	}
+	return m, nil
+}
+
+// readHeader returns the columns of the data as they will be uploaded
+func readHeader(opts ingestOptions, m *mapping.Mapping) ([]string, error) {
+	src, err := openSource(opts, m)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	r := csv.NewReader(src)
+	r.Comma = opts.comma()
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s is empty", opts.file)
+	}
+	return header, err
+}
+
+// checkpoint records the stage the load has reached in the ledger so that it can be resumed
+func checkpoint(e *ledger.Entry, stage, state string) {
+	e.Stage = stage
+	e.State = state
+	e.UpdatedAt = time.Now()
+	recordJob(*e)
+}
+
+// submitJob creates an ingest job for data that sfcli has prepared itself, such as a retry, uploads
+// the payload and starts the job, recording each stage in the ledger
+func submitJob(ctx context.Context, br salesforce.BulkRequest, payload io.Reader, parentID, source string) (*salesforce.JobInfo, error) {
+	job, err := app.sc.BulkService.CreateJob(ctx, br)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Job Created for %s: %s (%s)\n", br.Operation, job.ID, job.State)
+	e := newEntry(job)
+	e.ParentID = parentID
+	e.Source = source
+	checkpoint(&e, ledger.StageCreated, job.State)
+
+	if err := app.sc.BulkService.UploadCSV(ctx, job.ID, payload); err != nil {
+		return nil, err
+	}
+	checkpoint(&e, ledger.StageUploaded, job.State)
+	fmt.Println("Records uploaded, starting job...", job.ID)
+
+	res, err := app.sc.BulkService.ProcessJob(ctx, salesforce.BulkTypeIngest, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint(&e, ledger.StageStarted, res.State)
+	return res, nil
+}
+
+// entryOptions returns the settings used to load a file recorded in the ledger
+func entryOptions(e *ledger.Entry) ingestOptions {
+	return ingestOptions{
+		file:       e.Source,
+		object:     e.Object,
+		operation:  e.Operation,
+		externalID: e.ExternalID,
+		format:     e.Format,
+		sheet:      e.Sheet,
+		crlf:       e.CRLF,
+		delimiter:  e.Delimiter,
+		encoding:   e.Encoding,
+		mapFile:    e.Mapping,
+	}
+}
+
+// ledgerEntry returns the ledger entry for an ingest job, with the settings needed to run it again
+func ledgerEntry(job *salesforce.JobInfo, opts ingestOptions) ledger.Entry {
+	e := newEntry(job)
+	e.ParentID = opts.parentID
+	e.Format = opts.format
+	e.Sheet = opts.sheet
+	e.Delimiter = opts.delimiter
+	e.Encoding = opts.encoding
+	e.CRLF = opts.crlf
+	e.Source = opts.source
+	switch {
+	case opts.source != "":
+	case opts.file == stdinFile:
+		e.Source = "stdin"
+	default:
+		if path, err := filepath.Abs(opts.file); err == nil {
+			e.Source = path
+		}
+		if sum, err := ledger.Checksum(opts.file); err == nil {
+			e.Checksum = sum
+		}
+	}
+	if opts.mapFile != "" {
+		if path, err := filepath.Abs(opts.mapFile); err == nil {
+			e.Mapping = path
+		}
+	}
+	return e
+}
+
+// detectLayout converts csv input to UTF-8 and works out its delimiter and line ending, unless they have been provided
+func detectLayout(opts *ingestOptions) error {
+	var delimiter, lineEnding string
+	if opts.input != nil {
+		dec, err := convert.NewDecoder(opts.input, opts.encoding)
+		if err != nil {
+			return err
+		}
+		var all io.Reader
+		if delimiter, lineEnding, all, err = convert.SniffReader(dec); err != nil {
+			return err
+		}
+		opts.input = readCloser{all, opts.input}
+	} else {
+		src, err := openSource(*opts, nil)
+		if err != nil {
+			return err
+		}
+		delimiter, lineEnding, _, err = convert.SniffReader(src)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.delimiter != "" {
+		d, ok := convert.Delimiters[strings.ToLower(opts.delimiter)]
+		if !ok {
+			return fmt.Errorf("unsupported delimiter %s, use one of comma, tab, semicolon, pipe, caret or backquote", opts.delimiter)
+		}
+		delimiter = d
+	}
+	if opts.crlf {
+		lineEnding = "CRLF"
+	}
+	opts.columnDelimiter, opts.lineEnding = delimiter, lineEnding
+	return nil
+}
+
+// openSource opens the file to be loaded, converting it to UTF-8 CSV and applying the mapping,
+// if there is one, as it is read
+func openSource(opts ingestOptions, m *mapping.Mapping) (io.ReadCloser, error) {
+	var src io.ReadCloser
+	switch {
+	case opts.input != nil:
+		src = opts.input
+	case opts.buffered != nil:
+		src = convert.NewReader(bytes.NewReader(opts.buffered), convert.Format(opts.format), opts.sheet)
+	default:
+		file, err := os.Open(opts.file)
+		if err != nil {
+			return nil, err
+		}
+		src = file
+		if format := convert.Format(opts.format); format != convert.FormatCSV {
+			src = convert.NewReader(file, format, opts.sheet)
+		} else {
+			dec, err := convert.NewDecoder(file, opts.encoding)
+			if err != nil {
+				file.Close()
+				return nil, err
+			}
+			src = readCloser{dec, file}
+		}
+	}
+	if m != nil {
+		src = m.NewReader(src, opts.comma())
+	}
+	return src, nil
+}
+
+// validateFile checks the file against the describe metadata for the object without creating a job
+func validateFile(ctx context.Context, opts ingestOptions, m *mapping.Mapping) error {
+	src, err := openSource(opts, m)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	v, err := app.sc.NewValidator(ctx, opts.object, opts.operation, opts.externalID)
+	if err != nil {
+		return fmt.Errorf("problem describing %s for validation: %w", opts.object, err)
+	}
+	problems, rows, err := v.ValidateCSV(ctx, src, opts.comma())
+	if err != nil {
+		return fmt.Errorf("problem reading %s: %w", opts.file, err)
+	}
+	if len(problems) > 0 {
+		printValidationErrors(problems)
+		return fmt.Errorf("%w: %d problems found in %d records", errValidationFailed, len(problems), rows)
+	}
+	fmt.Printf("Validated %d records against %s\n", rows, opts.object)
+	return nil
+}
+
+// validateStream returns a reader that validates src as it is read.  Once the reader has been
+// read to the end, the returned function waits for the validation to finish and reports the result.
+func validateStream(ctx context.Context, opts ingestOptions, src io.ReadCloser) (io.ReadCloser, func() error, error) {
+	v, err := app.sc.NewValidator(ctx, opts.object, opts.operation, opts.externalID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem describing %s for validation: %w", opts.object, err)
+	}
+	pr, pw := io.Pipe()
+	type result struct {
+		problems []salesforce.ValidationError
+		rows     int
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		problems, rows, err := v.ValidateCSV(ctx, pr, opts.comma())
+		// keep reading so the upload isn't blocked if validation stopped early
+		io.Copy(ioutil.Discard, pr)
+		done <- result{problems, rows, err}
+	}()
+	validated := func() error {
+		pw.Close()
+		r := <-done
+		if r.err != nil {
+			return fmt.Errorf("problem reading input: %w", r.err)
+		}
+		if len(r.problems) > 0 {
+			printValidationErrors(r.problems)
+			return fmt.Errorf("validation failed: %d problems found in %d records", len(r.problems), r.rows)
+		}
+		fmt.Printf("Validated %d records against %s\n", r.rows, opts.object)
+		return nil
+	}
+	return &teeReadCloser{Reader: io.TeeReader(src, pw), source: src, pipe: pw}, validated, nil
+}
+
+// teeReadCloser closes both the source and the pipe it is copying to
+type teeReadCloser struct {
+	io.Reader
+	source io.Closer
+	pipe   *io.PipeWriter
+}
+
+func (t *teeReadCloser) Close() error {
+	t.pipe.Close()
+	return t.source.Close()
+}
+
+func printValidationErrors(problems []salesforce.ValidationError) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Println()
+	blue.Println("VALIDATION ERRORS")
+	tbl := table.New("Row", "Column", "Value", "Error")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, p := range problems {
+		row := fmt.Sprint(p.Row)
+		if p.Row == 0 {
+			row = "header"
+		}
+		tbl.AddRow(row, p.Column, p.Value, p.Message)
+	}
+	tbl.Print()
+	fmt.Println()
+}
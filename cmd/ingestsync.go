@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darrenparkinson/sfcli/pkg/ledger"
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// syncOperations are the bulk operations that have an equivalent in the sObject collections API
+var syncOperations = map[string]bool{"insert": true, "update": true, "upsert": true}
+
+// readRows reads the header and up to max records from src.  If there are more records than that,
+// rows is nil and rest returns everything read so far followed by the remainder of src.  If src
+// can't be parsed, it is left to the bulk API to report the problem.
+func readRows(src io.Reader, comma rune, max int) (rows [][]string, rest io.Reader) {
+	var buf bytes.Buffer
+	r := csv.NewReader(io.TeeReader(src, &buf))
+	r.Comma = comma
+	for len(rows) <= max+1 {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			break
+		}
+		rows = append(rows, row)
+	}
+	return nil, io.MultiReader(&buf, src)
+}
+
+// syncIngest loads the rows read by readRows with the sObject collections API rather than a bulk job.
+// The load is recorded in the ledger with a sync- id, and its results are written in the same form
+// as the results of a bulk job so that it can be reported on, retried and rolled back in the same way.
+func syncIngest(ctx context.Context, opts ingestOptions, rows [][]string, validated func() error) error {
+	if validated != nil {
+		if err := validated(); err != nil {
+			return err
+		}
+	}
+	if len(rows) < 2 {
+		return fmt.Errorf("no records to load")
+	}
+	records, err := syncRecords(ctx, opts.object, rows[0], rows[1:])
+	if err != nil {
+		return err
+	}
+
+	job := &salesforce.JobInfo{
+		ID:                  ledger.SyncID(time.Now()),
+		Object:              opts.object,
+		Operation:           opts.operation,
+		ExternalIDFieldName: opts.externalID,
+		State:               "InProgress",
+	}
+	entry := ledgerEntry(job, opts)
+	entry.Sync = true
+	entry.Results = job.ID
+	if app.ledger != nil {
+		entry.Results = filepath.Join(app.ledger.ResultsDir(), job.ID)
+	}
+	recordJob(entry)
+	fmt.Printf("Loading %d records with the sObject collections API: %s\n", len(records), job.ID)
+
+	var results []salesforce.SaveResult
+	cs := app.sc.CompositeService
+	switch opts.operation {
+	case "insert":
+		results, err = cs.CreateRecords(ctx, opts.object, records, false)
+	case "update":
+		results, err = cs.UpdateRecords(ctx, opts.object, records, false)
+	case "upsert":
+		results, err = cs.UpsertRecords(ctx, opts.object, opts.externalID, records, false)
+	default:
+		return fmt.Errorf("%s isn't supported by the sObject collections API", opts.operation)
+	}
+	failed := printSyncResults(opts, records, results)
+
+	entry.State = "JobComplete"
+	if err != nil {
+		entry.State = "Failed"
+	}
+	entry.RecordsProcessed = len(results)
+	entry.RecordsFailed = failed
+	entry.UpdatedAt = time.Now()
+	recordJob(entry)
+	if werr := writeSyncResults(entry.Results, rows, results); werr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to write the results of %s: %s\n", job.ID, werr)
+	} else if failed > 0 || len(results) < len(records) {
+		fmt.Printf("Results written to %s\n", entry.Results)
+	}
+
+	if err != nil {
+		return fmt.Errorf("problem loading records %d onwards: %w", len(results)+1, err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d records failed", failed, len(results))
+	}
+	return nil
+}
+
+// writeSyncResults writes the rows that were loaded to successful.csv and failed.csv in dir, with
+// the same sf__ columns as the results of a bulk job, and any rows that weren't loaded to unprocessed.csv
+func writeSyncResults(dir string, rows [][]string, results []salesforce.SaveResult) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	header := rows[0]
+	files := []struct {
+		name    string
+		columns []string
+		include func(i int) ([]string, bool)
+	}{
+		{"successful.csv", []string{"sf__Id", "sf__Created"}, func(i int) ([]string, bool) {
+			if i >= len(results) || !results[i].Success {
+				return nil, false
+			}
+			return []string{results[i].ID, strconv.FormatBool(results[i].Created)}, true
+		}},
+		{"failed.csv", []string{"sf__Id", "sf__Error"}, func(i int) ([]string, bool) {
+			if i >= len(results) || results[i].Success {
+				return nil, false
+			}
+			msgs := make([]string, len(results[i].Errors))
+			for j, e := range results[i].Errors {
+				msgs[j] = fmt.Sprintf("%s:%s:%s --", e.StatusCode, e.Message, strings.Join(e.Fields, ","))
+			}
+			return []string{results[i].ID, strings.Join(msgs, " ")}, true
+		}},
+		{"unprocessed.csv", nil, func(i int) ([]string, bool) {
+			return nil, i >= len(results)
+		}},
+	}
+	for _, file := range files {
+		f, err := os.Create(filepath.Join(dir, file.name))
+		if err != nil {
+			return err
+		}
+		w := csv.NewWriter(f)
+		w.Write(append(append([]string{}, file.columns...), header...))
+		for i, row := range rows[1:] {
+			if prefix, ok := file.include(i); ok {
+				w.Write(append(prefix, row...))
+			}
+		}
+		w.Flush()
+		err = w.Error()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncEntry returns the ledger entry for id if it is a synchronous load, or nil if it isn't
+func syncEntry(id string) *ledger.Entry {
+	if app.ledger == nil || !strings.HasPrefix(id, "sync-") {
+		return nil
+	}
+	e, err := app.ledger.Get(id)
+	if err != nil || !e.Sync {
+		return nil
+	}
+	return e
+}
+
+// syncJob returns the job information for a synchronous load from its ledger entry
+func syncJob(e *ledger.Entry) *salesforce.JobInfo {
+	return &salesforce.JobInfo{
+		ID:                     e.ID,
+		Object:                 e.Object,
+		Operation:              e.Operation,
+		ExternalIDFieldName:    e.ExternalID,
+		State:                  e.State,
+		ContentType:            "CSV",
+		ColumnDelimiter:        "COMMA", // results are always written with commas
+		NumberRecordsProcessed: e.RecordsProcessed,
+		NumberRecordsFailed:    e.RecordsFailed,
+	}
+}
+
+// syncRecords converts the rows of a bulk CSV to records for the collections API.  Values are
+// converted to the type of their field, an empty value is left out as it is by a bulk job, and
+// #N/A clears the field.  Relationship columns such as Account.ERP_Id__c refer to the related
+// record by an external ID.
+func syncRecords(ctx context.Context, object string, header []string, rows [][]string) ([]map[string]interface{}, error) {
+	dr, err := app.sc.Describe(ctx, object)
+	if err != nil {
+		return nil, fmt.Errorf("problem describing %s: %w", object, err)
+	}
+	types := make([]string, len(header))
+	for i, h := range header {
+		if f := dr.FieldByName(h); f != nil {
+			types[i] = f.Type
+		}
+	}
+	records := make([]map[string]interface{}, len(rows))
+	for n, row := range rows {
+		rec := make(map[string]interface{}, len(row))
+		for i, value := range row {
+			if i >= len(header) || value == "" {
+				continue
+			}
+			name := header[i]
+			if objectType, relationship, field, ok := salesforce.RelationshipColumn(name); ok {
+				// polymorphic relationships name the object, e.g. User:Owner.Username
+				if f := dr.FieldByRelationship(relationship); f != nil {
+					relationship = f.RelationshipName
+				}
+				related := map[string]interface{}{field: value}
+				if objectType != "" {
+					related["attributes"] = map[string]string{"type": objectType}
+				}
+				rec[relationship] = related
+				continue
+			}
+			if value == "#N/A" {
+				rec[name] = nil
+				continue
+			}
+			v, err := fieldValue(types[i], value)
+			if err != nil {
+				return nil, fmt.Errorf("record %d, %s: %w", n+1, name, err)
+			}
+			rec[name] = v
+		}
+		records[n] = rec
+	}
+	return records, nil
+}
+
+// printSyncResults summarises the results of a synchronous load and lists the records that
+// failed, returning how many did
+func printSyncResults(opts ingestOptions, records []map[string]interface{}, results []salesforce.SaveResult) int {
+	failed, created := 0, 0
+	for _, r := range results {
+		if !r.Success {
+			failed++
+		}
+		if r.Created {
+			created++
+		}
+	}
+	summary := fmt.Sprintf("Loaded %d %s records with %s: %d succeeded, %d failed", len(results), opts.object, opts.operation, len(results)-failed, failed)
+	if opts.operation == "upsert" {
+		summary += fmt.Sprintf(" (%d created)", created)
+	}
+	fmt.Println(summary)
+	if failed == 0 {
+		return 0
+	}
+
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Println()
+	blue.Println("Failed Records")
+	tbl := table.New("Record", "Id", "Errors")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for i, r := range results {
+		if r.Success {
+			continue
+		}
+		id := r.ID
+		if id == "" && i < len(records) {
+			id, _ = records[i]["Id"].(string)
+		}
+		msgs := make([]string, len(r.Errors))
+		for j, e := range r.Errors {
+			msgs[j] = e.Error()
+		}
+		tbl.AddRow(i+1, id, strings.Join(msgs, "; "))
+	}
+	tbl.Print()
+	fmt.Println()
+	return failed
+}
+
+// ingestJob returns an ingest job, or the job information in the ledger for a synchronous load
+func ingestJob(ctx context.Context, id string) (*salesforce.JobInfo, error) {
+	if e := syncEntry(id); e != nil {
+		return syncJob(e), nil
+	}
+	return app.sc.BulkService.GetJob(ctx, salesforce.BulkTypeIngest, id)
+}
+
+// Kinds of results returned by jobResults
+const (
+	resultsSuccessful  = "successful"
+	resultsFailed      = "failed"
+	resultsUnprocessed = "unprocessed"
+)
+
+// jobResults returns the successful, failed or unprocessed records of an ingest job, reading them
+// from the results directory for a synchronous load.  The caller must close the returned reader.
+func jobResults(ctx context.Context, id, kind string) (io.ReadCloser, error) {
+	if e := syncEntry(id); e != nil {
+		return os.Open(filepath.Join(e.Results, kind+".csv"))
+	}
+	var res string
+	var err error
+	switch kind {
+	case resultsSuccessful:
+		res, err = app.sc.BulkService.GetSuccessfulResults(ctx, salesforce.BulkTypeIngest, id)
+	case resultsFailed:
+		res, err = app.sc.BulkService.GetFailedResults(ctx, salesforce.BulkTypeIngest, id)
+	default:
+		return app.sc.BulkService.GetUnprocessedRecords(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(strings.NewReader(res)), nil
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darrenparkinson/sfcli/pkg/plan"
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var loadCmd = &cobra.Command{
+	Use:   "load <plan.yaml>",
+	Short: "Load several related files in dependency order using a plan",
+	Args:  cobra.ExactArgs(1),
+	Run:   load,
+}
+
+func init() {
+	rootCmd.AddCommand(loadCmd)
+
+	loadCmd.Flags().String("policy", "", "What to do when a step fails: stop or continue (default is the policy in the plan)")
+	viper.BindPFlag("loadPolicy", loadCmd.Flags().Lookup("policy"))
+
+	loadCmd.Flags().Bool("skip-validation", false, "Skip validating the files against the objects' fields before creating the jobs")
+	viper.BindPFlag("loadSkipValidation", loadCmd.Flags().Lookup("skip-validation"))
+
+	loadCmd.Flags().Bool("validate-only", false, "Validate the files and show the order they would be loaded in without creating any jobs")
+	viper.BindPFlag("loadValidateOnly", loadCmd.Flags().Lookup("validate-only"))
+}
+
+// stepResult is the outcome of a single step of a plan
+type stepResult struct {
+	step   plan.Step
+	job    *salesforce.JobInfo
+	result string // Complete, Failed, Skipped, Not Run or Valid
+	err    error
+}
+
+func (r stepResult) failed() bool {
+	return r.result == "Failed" || r.result == "Skipped"
+}
+
+func load(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	p, err := plan.Load(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if policy := viper.GetString("loadPolicy"); policy != "" {
+		p.Policy = policy
+		if err := p.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	validateOnly := viper.GetBool("loadValidateOnly")
+
+	levels, err := p.Order(stepReferences(ctx))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	printPlan(levels)
+
+	results := make(map[string]*stepResult)
+	stopped := false
+	for _, level := range levels {
+		var wg sync.WaitGroup
+		for _, s := range level {
+			r := &stepResult{step: s}
+			results[s.Name] = r
+			if stopped {
+				r.result = "Not Run"
+				continue
+			}
+			if failed := failedDependencies(s, results); len(failed) > 0 {
+				r.result = "Skipped"
+				r.err = fmt.Errorf("depends on %s", strings.Join(failed, ", "))
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runStep(ctx, r, validateOnly)
+			}()
+		}
+		wg.Wait()
+
+		if p.Policy == plan.PolicyStop {
+			for _, s := range level {
+				if results[s.Name].failed() {
+					stopped = true
+				}
+			}
+		}
+	}
+
+	printStepResults(levels, results)
+	for _, r := range results {
+		if r.failed() || r.result == "Not Run" {
+			os.Exit(1)
+		}
+	}
+}
+
+// stepReferences returns a function that finds the objects referred to by the columns of each step's file
+func stepReferences(ctx context.Context) func(s plan.Step) ([]string, error) {
+	describes := make(map[string]*salesforce.DescribeResponse)
+	return func(s plan.Step) ([]string, error) {
+		opts := stepOptions(s)
+		m, err := prepareIngest(&opts)
+		if err != nil {
+			return nil, err
+		}
+		header, err := readHeader(opts, m)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(s.Object)
+		dr, ok := describes[key]
+		if !ok {
+			if dr, err = app.sc.Describe(ctx, s.Object); err != nil {
+				return nil, fmt.Errorf("problem describing %s: %w", s.Object, err)
+			}
+			describes[key] = dr
+		}
+		return dr.References(header), nil
+	}
+}
+
+// stepOptions returns the ingest settings for a step of the plan
+func stepOptions(s plan.Step) ingestOptions {
+	return ingestOptions{
+		file:           s.File,
+		object:         s.Object,
+		operation:      s.Operation,
+		externalID:     s.External,
+		format:         s.Format,
+		sheet:          s.Sheet,
+		crlf:           s.CRLF,
+		delimiter:      s.Delimiter,
+		encoding:       s.Encoding,
+		mapFile:        s.Map,
+		skipValidation: viper.GetBool("loadSkipValidation"),
+		validateOnly:   viper.GetBool("loadValidateOnly"),
+	}
+}
+
+// failedDependencies returns the names of the steps that s depends on that didn't complete
+func failedDependencies(s plan.Step, results map[string]*stepResult) []string {
+	var failed []string
+	for _, d := range s.DependsOn {
+		if r, ok := results[d]; ok && r.result != "Complete" && r.result != "Valid" {
+			failed = append(failed, d)
+		}
+	}
+	return failed
+}
+
+// runStep loads the file for a step and waits for the job to finish, since later steps may refer to its records
+func runStep(ctx context.Context, r *stepResult, validateOnly bool) {
+	job, err := runIngest(ctx, stepOptions(r.step))
+	if err != nil {
+		r.result, r.err = "Failed", err
+		return
+	}
+	if validateOnly {
+		r.result = "Valid"
+		return
+	}
+	fmt.Printf("Waiting for %s job %s to finish...\n", r.step.Name, job.ID)
+	if job, err = app.sc.BulkService.WaitForJob(ctx, salesforce.BulkTypeIngest, job.ID, 5*time.Second); err != nil {
+		r.result, r.err = "Failed", err
+		return
+	}
+	refreshJob(job)
+	r.job = job
+	switch {
+	case job.State != "JobComplete":
+		r.result, r.err = "Failed", fmt.Errorf("job %s: %s", job.State, job.ErrorMessage)
+	case job.NumberRecordsFailed > 0:
+		r.result, r.err = "Failed", fmt.Errorf("%d records failed, see bulk report %s", job.NumberRecordsFailed, job.ID)
+	default:
+		r.result = "Complete"
+	}
+}
+
+func printPlan(levels [][]plan.Step) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Println()
+	blue.Println("LOAD PLAN")
+	tbl := table.New("Level", "Step", "Object", "Operation", "File", "After")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for i, level := range levels {
+		for _, s := range level {
+			tbl.AddRow(i+1, s.Name, s.Object, s.Operation, s.File, strings.Join(s.DependsOn, ", "))
+		}
+	}
+	tbl.Print()
+	fmt.Println()
+}
+
+func printStepResults(levels [][]plan.Step, results map[string]*stepResult) {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Println()
+	blue.Println("LOAD RESULTS")
+	tbl := table.New("Step", "Object", "Job", "Status", "Processed", "Failed", "Result", "Error")
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, level := range levels {
+		for _, s := range level {
+			r := results[s.Name]
+			var id, state string
+			var processed, failed int
+			if r.job != nil {
+				id, state = r.job.ID, r.job.State
+				processed, failed = r.job.NumberRecordsProcessed, r.job.NumberRecordsFailed
+			}
+			var msg string
+			if r.err != nil {
+				msg = r.err.Error()
+			}
+			tbl.AddRow(s.Name, s.Object, id, state, processed, failed, r.result, msg)
+		}
+	}
+	tbl.Print()
+	fmt.Println()
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var objectsCmd = &cobra.Command{
+	Use:   "objects [name]",
+	Short: "List the objects in the org, optionally those whose name or label contains the given text",
+	Example: `  sfcli objects --custom --queryable
+  sfcli objects invoice
+  sfcli objects --prefix 001 -o json`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  objects,
+}
+
+func init() {
+	rootCmd.AddCommand(objectsCmd)
+
+	objectsCmd.Flags().Bool("custom", false, "Only list custom objects")
+	viper.BindPFlag("objectsCustom", objectsCmd.Flags().Lookup("custom"))
+
+	objectsCmd.Flags().Bool("standard", false, "Only list standard objects")
+	viper.BindPFlag("objectsStandard", objectsCmd.Flags().Lookup("standard"))
+
+	objectsCmd.Flags().Bool("queryable", false, "Only list objects that can be queried")
+	viper.BindPFlag("objectsQueryable", objectsCmd.Flags().Lookup("queryable"))
+
+	objectsCmd.Flags().Bool("createable", false, "Only list objects that records can be created for")
+	viper.BindPFlag("objectsCreateable", objectsCmd.Flags().Lookup("createable"))
+
+	objectsCmd.Flags().String("prefix", "", "Only list the object with this key prefix, the first three characters of its record ids")
+	viper.BindPFlag("objectsPrefix", objectsCmd.Flags().Lookup("prefix"))
+
+	objectsCmd.Flags().StringP("output", "o", outputTable, "Output format: "+outputFormats)
+	viper.BindPFlag("objectsOutput", objectsCmd.Flags().Lookup("output"))
+}
+
+func objects(cmd *cobra.Command, args []string) {
+	custom, standard := viper.GetBool("objectsCustom"), viper.GetBool("objectsStandard")
+	if custom && standard {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: use only one of --custom and --standard")
+		os.Exit(1)
+	}
+	dg, err := app.sc.DescribeGlobal(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	search := ""
+	if len(args) > 0 {
+		search = strings.ToLower(args[0])
+	}
+	prefix := viper.GetString("objectsPrefix")
+	var list []salesforce.SObjectInfo
+	for _, o := range dg.SObjects {
+		switch {
+		case custom && !o.Custom, standard && o.Custom:
+		case viper.GetBool("objectsQueryable") && !o.Queryable:
+		case viper.GetBool("objectsCreateable") && !o.Createable:
+		case prefix != "" && !strings.EqualFold(o.KeyPrefix, prefix):
+		case search != "" && !strings.Contains(strings.ToLower(o.Name), search) && !strings.Contains(strings.ToLower(o.Label), search):
+		default:
+			list = append(list, o)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name) })
+
+	format := viper.GetString("objectsOutput")
+	columns := []string{"Name", "Label", "KeyPrefix", "Custom", "Queryable", "Createable", "Updateable", "Deletable"}
+	w, err := newRowWriter(os.Stdout, format, "Objects", columns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	for _, o := range list {
+		if err := w.Write([]interface{}{o.Name, o.Label, o.KeyPrefix, o.Custom, o.Queryable, o.Createable, o.Updateable, o.Deletable}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if strings.EqualFold(format, outputTable) {
+		fmt.Printf("%d of %d objects\n", len(list), len(dg.SObjects))
+	}
+}
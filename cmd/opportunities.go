@@ -10,5 +10,6 @@ var opportunitiesCmd = &cobra.Command{
 }
 
 func init() {
-	// rootCmd.AddCommand(opportunitiesCmd)
+	rootCmd.AddCommand(opportunitiesCmd)
+	addRecordCommands(opportunitiesCmd, "Opportunity")
 }
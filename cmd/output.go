@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// Output formats for commands that print rows of data
+const (
+	outputTable    = "table"
+	outputCSV      = "csv"
+	outputJSON     = "json"
+	outputNDJSON   = "ndjson"
+	outputMarkdown = "markdown"
+)
+
+// outputFormats lists the formats for use in flag descriptions
+var outputFormats = strings.Join([]string{outputTable, outputCSV, outputJSON, outputNDJSON, outputMarkdown}, ", ")
+
+// rowWriter writes rows of values under a fixed set of columns in one of the output formats.
+// Values are written as they are for JSON, and as text for tables and CSV.
+type rowWriter interface {
+	Write(values []interface{}) error
+	// Flush writes anything that has been buffered, which for a table is the whole table
+	Flush() error
+}
+
+// newRowWriter returns a writer for the given format.  The title is only used for tables.
+func newRowWriter(w io.Writer, format, title string, columns []string) (rowWriter, error) {
+	switch strings.ToLower(format) {
+	case outputTable, "":
+		return &tableWriter{w: w, title: title, columns: columns}, nil
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return nil, err
+		}
+		return &csvRowWriter{w: cw}, nil
+	case outputJSON:
+		return &jsonRowWriter{w: w, columns: columns}, nil
+	case outputNDJSON:
+		return &jsonRowWriter{w: w, columns: columns, lines: true}, nil
+	case outputMarkdown:
+		return &markdownWriter{w: w, title: title, columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unsupported output format %s, use one of %s", format, outputFormats)
+}
+
+// formatValue returns the text for a value in a table or CSV, with anything other than
+// a simple value written as JSON
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool, int, int64, float64:
+		return fmt.Sprint(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+type tableWriter struct {
+	w       io.Writer
+	title   string
+	columns []string
+	rows    [][]interface{}
+}
+
+func (t *tableWriter) Write(values []interface{}) error {
+	row := make([]interface{}, len(values))
+	for i, v := range values {
+		row[i] = formatValue(v)
+	}
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+func (t *tableWriter) Flush() error {
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Fprintln(t.w)
+	if t.title != "" {
+		blue.Fprintln(t.w, t.title)
+	}
+	headers := make([]interface{}, len(t.columns))
+	for i, c := range t.columns {
+		headers[i] = c
+	}
+	tbl := table.New(headers...).WithWriter(t.w)
+	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for _, row := range t.rows {
+		tbl.AddRow(row...)
+	}
+	tbl.Print()
+	fmt.Fprintln(t.w)
+	return nil
+}
+
+// markdownWriter writes a table in GitHub flavoured markdown, with the title as a heading
+type markdownWriter struct {
+	w       io.Writer
+	title   string
+	columns []string
+	started bool
+}
+
+// markdownEscaper escapes the characters that would break a markdown table cell
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func (m *markdownWriter) Write(values []interface{}) error {
+	if err := m.header(); err != nil {
+		return err
+	}
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = markdownEscaper.Replace(formatValue(v))
+	}
+	_, err := fmt.Fprintf(m.w, "| %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+func (m *markdownWriter) header() error {
+	if m.started {
+		return nil
+	}
+	m.started = true
+	if m.title != "" {
+		if _, err := fmt.Fprintf(m.w, "### %s\n\n", m.title); err != nil {
+			return err
+		}
+	}
+	rule := make([]string, len(m.columns))
+	for i := range rule {
+		rule[i] = "---"
+	}
+	_, err := fmt.Fprintf(m.w, "| %s |\n| %s |\n", strings.Join(m.columns, " | "), strings.Join(rule, " | "))
+	return err
+}
+
+func (m *markdownWriter) Flush() error {
+	if err := m.header(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(m.w)
+	return err
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) Write(values []interface{}) error {
+	row := make([]string, len(values))
+	for i, v := range values {
+		row[i] = formatValue(v)
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvRowWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonRowWriter writes each row as an object with the fields in column order, either
+// as an array or, for NDJSON, one object per line
+type jsonRowWriter struct {
+	w       io.Writer
+	columns []string
+	lines   bool
+	count   int
+}
+
+func (j *jsonRowWriter) Write(values []interface{}) error {
+	var buf strings.Builder
+	buf.WriteByte('{')
+	for i, c := range j.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(c)
+		var v interface{}
+		if i < len(values) {
+			v = values[i]
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	sep := "\n"
+	if !j.lines {
+		sep = ",\n  "
+		if j.count == 0 {
+			sep = "[\n  "
+		}
+	}
+	j.count++
+	if j.lines {
+		_, err := io.WriteString(j.w, buf.String()+sep)
+		return err
+	}
+	_, err := io.WriteString(j.w, sep+buf.String())
+	return err
+}
+
+func (j *jsonRowWriter) Flush() error {
+	if j.lines {
+		return nil
+	}
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
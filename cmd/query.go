@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// queryColumnSample is the number of records examined to work out the columns, since a
+// relationship that is null in one record may have fields in another
+const queryColumnSample = 2000
+
+var queryCmd = &cobra.Command{
+	Use:   "query <soql>",
+	Short: "Run a SOQL query",
+	Args:  cobra.ExactArgs(1),
+	Run:   query,
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().Bool("all-rows", false, "Include deleted and archived records")
+	viper.BindPFlag("queryAllRows", queryCmd.Flags().Lookup("all-rows"))
+
+	queryCmd.Flags().StringP("output", "o", outputTable, "Output format: "+outputFormats)
+	viper.BindPFlag("queryOutput", queryCmd.Flags().Lookup("output"))
+}
+
+func query(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	run := app.sc.Query
+	if viper.GetBool("queryAllRows") {
+		run = app.sc.QueryAll
+	}
+	statement, err := expandFields(ctx, app.sc, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	it, err := run(ctx, statement)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+
+	var sample []*salesforce.Record
+	for len(sample) < queryColumnSample && it.Next() {
+		sample = append(sample, it.Record().Flatten())
+	}
+	if err := it.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	columns := recordColumns(sample)
+
+	format := viper.GetString("queryOutput")
+	w, err := newRowWriter(os.Stdout, format, "QUERY RESULTS", columns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	write := func(r *salesforce.Record) {
+		values := make([]interface{}, len(columns))
+		for i, c := range columns {
+			values[i], _ = r.Get(c)
+		}
+		if err := w.Write(values); err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, r := range sample {
+		write(r)
+	}
+	for it.Next() {
+		write(it.Record().Flatten())
+	}
+	if err := it.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if strings.EqualFold(format, outputTable) {
+		fmt.Printf("%d records\n", it.TotalSize())
+	}
+}
+
+// recordColumns returns the fields of the flattened records in the order they first appear.  A null
+// relationship is left out when the fields of the related record appear in other records.
+func recordColumns(records []*salesforce.Record) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, r := range records {
+		for _, f := range r.Fields() {
+			if !seen[f] {
+				seen[f] = true
+				columns = append(columns, f)
+			}
+		}
+	}
+	// put the fields of a relationship where it first appeared, in place of the null
+	hasRelated := func(c string) bool {
+		for _, other := range columns {
+			if strings.HasPrefix(other, c+".") {
+				return true
+			}
+		}
+		return false
+	}
+	var result []string
+	added := make(map[string]bool)
+	for _, c := range columns {
+		if added[c] {
+			continue
+		}
+		if !hasRelated(c) {
+			added[c] = true
+			result = append(result, c)
+			continue
+		}
+		for _, other := range columns {
+			if strings.HasPrefix(other, c+".") && !added[other] && !hasRelated(other) {
+				added[other] = true
+				result = append(result, other)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+var queryExplainCmd = &cobra.Command{
+	Use:   "explain <soql>",
+	Short: "Show the query plans salesforce would use for a SOQL query, report or list view",
+	Args:  cobra.ExactArgs(1),
+	Run:   queryExplain,
+}
+
+func init() {
+	queryCmd.AddCommand(queryExplainCmd)
+}
+
+func queryExplain(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	statement, err := expandFields(ctx, app.sc, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	er, err := app.sc.Explain(ctx, statement)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	printPlans(er.Plans)
+}
+
+// printPlans prints the plans ranked by relative cost, cheapest first, followed by their notes
+func printPlans(plans []salesforce.QueryPlan) {
+	sort.SliceStable(plans, func(i, j int) bool { return plans[i].RelativeCost < plans[j].RelativeCost })
+
+	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	columnFmt := color.New(color.FgYellow).SprintfFunc()
+	blue := color.New(color.FgHiBlue)
+	fmt.Println()
+	blue.Println("Query Plans")
+	tblPlans := table.New("Rank", "Leading Operation", "Relative Cost", "Cardinality", "SObject Cardinality", "SObject Type", "Fields")
+	tblPlans.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	notes := 0
+	for i, p := range plans {
+		tblPlans.AddRow(i+1, p.LeadingOperationType, fmt.Sprintf("%.4g", p.RelativeCost), p.Cardinality, p.SobjectCardinality, p.SobjectType, strings.Join(p.Fields, ", "))
+		notes += len(p.Notes)
+	}
+	tblPlans.Print()
+	fmt.Println()
+	if notes == 0 {
+		return
+	}
+	blue.Println("Notes")
+	tblNotes := table.New("Rank", "Table", "Fields", "Description")
+	tblNotes.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
+	for i, p := range plans {
+		for _, n := range p.Notes {
+			tblNotes.AddRow(i+1, n.TableEnumOrID, strings.Join(n.Fields, ", "), n.Description)
+		}
+	}
+	tblNotes.Print()
+	fmt.Println()
+}
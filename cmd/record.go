@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Flags shared by the record commands of each object
+var (
+	recordFields   []string
+	recordExternal string
+	recordJSON     string
+	recordOutput   string
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Get, create, update, upsert and delete single records of any object",
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+	recordCmd.PersistentFlags().StringVarP(&sobject, "sobject", "s", "", "Type of SObject, e.g. Account, Contact, Opportunity")
+	viper.BindPFlag("sobject", recordCmd.PersistentFlags().Lookup("sobject"))
+	addRecordCommands(recordCmd, "")
+}
+
+// addRecordCommands adds the get, create, update, upsert and delete commands to parent.  When
+// object is empty it is taken from the --sobject flag.
+func addRecordCommands(parent *cobra.Command, object string) {
+	objectName := func() string {
+		if object != "" {
+			return object
+		}
+		o := viper.GetString("sobject")
+		if o == "" {
+			fmt.Fprintln(os.Stderr, "Error executing CLI: sobject type is required")
+			os.Exit(1)
+		}
+		return o
+	}
+	what := "a record"
+	if object != "" {
+		what = "an " + object
+		if !strings.ContainsAny(object[:1], "AEIOU") {
+			what = "a " + object
+		}
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show " + what,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			recordGet(objectName(), args[0])
+		},
+	}
+	getCmd.Flags().StringSliceVar(&recordFields, "fields", nil, "Fields to show (default all)")
+	viper.BindPFlag("recordFields", getCmd.Flags().Lookup("fields"))
+	getCmd.Flags().StringVarP(&recordExternal, "external", "e", "", "External ID field to find the record by, in place of its id")
+	viper.BindPFlag("recordExternal", getCmd.Flags().Lookup("external"))
+	getCmd.Flags().StringVarP(&recordOutput, "output", "o", outputTable, "Output format: "+outputFormats)
+	viper.BindPFlag("recordOutput", getCmd.Flags().Lookup("output"))
+
+	createCmd := &cobra.Command{
+		Use:   "create [field=value]...",
+		Short: "Create " + what,
+		Run: func(cmd *cobra.Command, args []string) {
+			recordCreate(objectName(), args)
+		},
+	}
+
+	updateCmd := &cobra.Command{
+		Use:   "update <id> [field=value]...",
+		Short: "Update " + what,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			recordUpdate(objectName(), args[0], args[1:])
+		},
+	}
+
+	upsertCmd := &cobra.Command{
+		Use:   "upsert <external id value> [field=value]...",
+		Short: "Create or update " + what + " by an external ID",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			recordUpsert(objectName(), args[0], args[1:])
+		},
+	}
+	upsertCmd.Flags().StringVarP(&recordExternal, "external", "e", "", "External ID field, required")
+	viper.BindPFlag("recordExternal", upsertCmd.Flags().Lookup("external"))
+
+	for _, c := range []*cobra.Command{createCmd, updateCmd, upsertCmd} {
+		c.Flags().StringVar(&recordJSON, "json", "", "JSON file of field values, or - for stdin, which field=value arguments override")
+		viper.BindPFlag("recordJSON", c.Flags().Lookup("json"))
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete " + what,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			recordDelete(objectName(), args[0])
+		},
+	}
+
+	parent.AddCommand(getCmd, createCmd, updateCmd, upsertCmd, deleteCmd)
+}
+
+func recordGet(object, id string) {
+	ctx := context.Background()
+	fields := viper.GetStringSlice("recordFields")
+	var r *salesforce.Record
+	var err error
+	if external := viper.GetString("recordExternal"); external != "" {
+		r, err = app.sc.SObjectService.GetByExternalID(ctx, object, external, id, fields...)
+	} else {
+		r, err = app.sc.SObjectService.Get(ctx, object, id, fields...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if err := printRecord(r.Flatten(), object, viper.GetString("recordOutput")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// printRecord writes a record as a table of fields and values, or as a single row in the other formats
+func printRecord(r *salesforce.Record, object, format string) error {
+	if strings.EqualFold(format, outputTable) {
+		w, err := newRowWriter(os.Stdout, format, object, []string{"Field", "Value"})
+		if err != nil {
+			return err
+		}
+		for _, f := range r.Fields() {
+			v, _ := r.Get(f)
+			if err := w.Write([]interface{}{f, v}); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+	w, err := newRowWriter(os.Stdout, format, object, r.Fields())
+	if err != nil {
+		return err
+	}
+	return writeRecords(w, r.Fields(), []*salesforce.Record{r})
+}
+
+func recordCreate(object string, args []string) {
+	ctx := context.Background()
+	fields, err := recordValues(ctx, object, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	res, err := app.sc.SObjectService.Create(ctx, object, fields)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created %s %s\n", object, res.ID)
+}
+
+func recordUpdate(object, id string, args []string) {
+	ctx := context.Background()
+	fields, err := recordValues(ctx, object, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if err := app.sc.SObjectService.Update(ctx, object, id, fields); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Updated %s %s\n", object, id)
+}
+
+func recordUpsert(object, value string, args []string) {
+	ctx := context.Background()
+	external := viper.GetString("recordExternal")
+	if external == "" {
+		fmt.Fprintln(os.Stderr, "Error executing CLI: external id is required")
+		os.Exit(1)
+	}
+	fields, err := recordValues(ctx, object, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	res, err := app.sc.SObjectService.Upsert(ctx, object, external, value, fields)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	action := "Updated"
+	if res.Created {
+		action = "Created"
+	}
+	id := res.ID
+	if id == "" {
+		id = external + " " + value
+	}
+	fmt.Printf("%s %s %s\n", action, object, id)
+}
+
+func recordDelete(object, id string) {
+	if err := app.sc.SObjectService.Delete(context.Background(), object, id); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted %s %s\n", object, id)
+}
+
+// recordValues returns the field values from the --json file and the field=value arguments.  Arguments
+// are converted to the type of the field, an empty value clears the field, and a relationship such as
+// Account.ERP_Id__c=123 refers to the related record by an external ID.
+func recordValues(ctx context.Context, object string, args []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if name := viper.GetString("recordJSON"); name != "" {
+		var r io.Reader = os.Stdin
+		if name != "-" {
+			f, err := os.Open(name)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		}
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("problem reading %s: %w", name, err)
+		}
+	}
+	if len(args) == 0 {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("no field values given")
+		}
+		return values, nil
+	}
+
+	dr, err := app.sc.Describe(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		p := strings.Index(arg, "=")
+		if p <= 0 {
+			return nil, fmt.Errorf("expected field=value, not %s", arg)
+		}
+		name, value := arg[:p], arg[p+1:]
+		if dot := strings.Index(name, "."); dot > 0 {
+			f := dr.FieldByRelationship(name[:dot])
+			if f == nil {
+				return nil, fmt.Errorf("%s has no relationship %s", object, name[:dot])
+			}
+			values[f.RelationshipName] = map[string]interface{}{name[dot+1:]: value}
+			continue
+		}
+		f := dr.FieldByName(name)
+		if f == nil {
+			return nil, fmt.Errorf("%s has no field %s", object, name)
+		}
+		v, err := fieldValue(f.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values[f.Name] = v
+	}
+	return values, nil
+}
+
+// fieldValue converts a value from the command line to the JSON type of the field
+func fieldValue(fieldType, value string) (interface{}, error) {
+	if value == "" {
+		return nil, nil
+	}
+	switch fieldType {
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "int":
+		return strconv.Atoi(value)
+	case "double", "currency", "percent":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(value), nil
+	}
+	return value, nil
+}
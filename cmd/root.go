@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/darrenparkinson/sfcli/pkg/ledger"
 	"github.com/darrenparkinson/sfcli/pkg/salesforce"
 	"github.com/spf13/cobra"
 
@@ -20,11 +22,17 @@ type Config struct {
 }
 
 // App represents the running application and holds a reference to our salesforce client
+// and the local ledger of jobs we have created
 type App struct {
 	config Config
 	sc     *salesforce.Client
+	ledger *ledger.Ledger
 }
 
+// offlineAnnotation marks a command that can run without connecting to salesforce.  Its value is
+// "always", or the name of a flag that makes the command work offline when it is set.
+const offlineAnnotation = "offline"
+
 var cfgFile string
 var config Config
 var app App
@@ -50,6 +58,9 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.sfcli.yaml)")
 
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Download object descriptions rather than using the cached ones")
+	viper.BindPFlag("noCache", rootCmd.PersistentFlags().Lookup("no-cache"))
+
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	// rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
@@ -81,16 +92,81 @@ func initConfig() {
 	}
 
 	viper.Unmarshal(&config)
-	if config.Username == "" || config.Password == "" || config.ClientID == "" || config.ClientSecret == "" || config.BaseURL == "" {
+	app = App{config: config}
+
+	// the ledger is optional, so we carry on without it if there is no config directory
+	if path, err := ledger.DefaultPath(); err == nil {
+		app.ledger = ledger.New(path)
+	}
+
+	// commands that work offline don't need credentials, but still use the client for its cache if they are there
+	if !config.complete() {
+		if cmd, _, err := rootCmd.Find(os.Args[1:]); err == nil && worksOffline(cmd) {
+			return
+		}
 		fmt.Fprintln(os.Stderr, "Error executing CLI: Missing required environment variables")
 		os.Exit(1)
 	}
 
-	sc, err := salesforce.NewClient(config.BaseURL, config.Username, config.Password, config.ClientID, config.ClientSecret, nil)
+	sc, err := newClient(config)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error executing CLI: Problem initialising salesforce client")
 		os.Exit(1)
 	}
-	app = App{config, sc}
+	app.sc = sc
+}
 
+// worksOffline reports whether the command, with the flags it has been given, can run without
+// connecting to salesforce
+func worksOffline(cmd *cobra.Command) bool {
+	name, ok := cmd.Annotations[offlineAnnotation]
+	if !ok {
+		return false
+	}
+	if name == "always" {
+		return true
+	}
+	f := cmd.Flags().Lookup(name)
+	return f != nil && f.Value.String() == "true"
+}
+
+// complete reports whether all the settings required to connect to salesforce are present
+func (c Config) complete() bool {
+	return c.Username != "" && c.Password != "" && c.ClientID != "" && c.ClientSecret != "" && c.BaseURL != ""
+}
+
+// newClient returns a salesforce client for the given configuration.  Object descriptions are
+// cached unless --no-cache is given.
+func newClient(c Config) (*salesforce.Client, error) {
+	sc, err := salesforce.NewClient(c.BaseURL, c.Username, c.Password, c.ClientID, c.ClientSecret, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !viper.GetBool("noCache") {
+		if dir, err := describeCacheDir(); err == nil {
+			sc.DescribeCache = salesforce.NewFileCache(dir)
+		}
+	}
+	return sc, nil
+}
+
+// loadConfig reads the configuration for another org from the named file.  Unlike the main
+// configuration, environment variables are not used since they would refer to the main org.
+func loadConfig(name string) (Config, error) {
+	v := viper.New()
+	v.SetConfigFile(name)
+	if filepath.Ext(name) == "" {
+		v.SetConfigType("yaml")
+	}
+	var c Config
+	if err := v.ReadInConfig(); err != nil {
+		return c, err
+	}
+	if err := v.Unmarshal(&c); err != nil {
+		return c, err
+	}
+	if !c.complete() {
+		return c, fmt.Errorf("missing required settings in %s", name)
+	}
+	return c, nil
 }
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// searchScopes are the groups of fields a search can look in
+var searchScopes = []string{"all", "name", "email", "phone", "sidebar"}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <terms>",
+	Short: "Search for records across objects with SOSL",
+	Example: `  sfcli search "Acme" --in name --returning "Account(Id,Name),Contact(Id,Email)"
+  sfcli search "jo*" --returning "Contact(Id,Name WHERE MailingCountry = 'UK')" -o csv`,
+	Args: cobra.ExactArgs(1),
+	Run:  search,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().String("in", "all", "Fields to search: "+strings.Join(searchScopes, ", "))
+	viper.BindPFlag("searchIn", searchCmd.Flags().Lookup("in"))
+
+	searchCmd.Flags().String("returning", "", "Objects and fields to return, e.g. Account(Id,Name),Contact(Id,Email)")
+	viper.BindPFlag("searchReturning", searchCmd.Flags().Lookup("returning"))
+
+	searchCmd.Flags().Int("limit", 0, "Maximum number of records to return")
+	viper.BindPFlag("searchLimit", searchCmd.Flags().Lookup("limit"))
+
+	searchCmd.Flags().StringP("output", "o", outputTable, "Output format: "+outputFormats)
+	viper.BindPFlag("searchOutput", searchCmd.Flags().Lookup("output"))
+}
+
+func search(cmd *cobra.Command, args []string) {
+	scope := strings.ToLower(viper.GetString("searchIn"))
+	valid := false
+	for _, s := range searchScopes {
+		valid = valid || s == scope
+	}
+	if !valid {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: unsupported search scope %s, use one of %s\n", scope, strings.Join(searchScopes, ", "))
+		os.Exit(1)
+	}
+	sosl := fmt.Sprintf("FIND {%s} IN %s FIELDS", salesforce.EscapeSOSL(args[0]), strings.ToUpper(scope))
+	if returning := viper.GetString("searchReturning"); returning != "" {
+		sosl += " RETURNING " + returning
+	}
+	if limit := viper.GetInt("searchLimit"); limit > 0 {
+		sosl += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	sr, err := app.sc.Search(context.Background(), sosl)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+	if err := printSearchResults(sr, viper.GetString("searchOutput")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// printSearchResults writes the records grouped by object type.  Tables are printed one per type,
+// while the other formats write every record with its type in the first column.
+func printSearchResults(sr *salesforce.SearchResponse, format string) error {
+	types := sr.Types()
+	flat := make(map[string][]*salesforce.Record)
+	var all []*salesforce.Record
+	for _, t := range types {
+		for _, r := range sr.Records(t) {
+			flat[t] = append(flat[t], r.Flatten())
+		}
+		all = append(all, flat[t]...)
+	}
+
+	if strings.EqualFold(format, outputTable) {
+		for _, t := range types {
+			columns := recordColumns(flat[t])
+			w, err := newRowWriter(os.Stdout, format, t, columns)
+			if err != nil {
+				return err
+			}
+			if err := writeRecords(w, columns, flat[t]); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("%d records\n", len(all))
+		return nil
+	}
+
+	columns := recordColumns(all)
+	w, err := newRowWriter(os.Stdout, format, "", append([]string{"Type"}, columns...))
+	if err != nil {
+		return err
+	}
+	for _, r := range all {
+		values := []interface{}{r.Attributes.Type}
+		for _, c := range columns {
+			v, _ := r.Get(c)
+			values = append(values, v)
+		}
+		if err := w.Write(values); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// writeRecords writes the values of the columns for each record, then flushes the writer
+func writeRecords(w rowWriter, columns []string, records []*salesforce.Record) error {
+	for _, r := range records {
+		values := make([]interface{}, len(columns))
+		for i, c := range columns {
+			values[i], _ = r.Get(c)
+		}
+		if err := w.Write(values); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
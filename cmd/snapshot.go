@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/mapping"
+)
+
+// takeSnapshot saves the current values of the columns in the file for the records it will update,
+// so that the load can be rolled back.  The snapshot is written to a temporary file in the snapshot
+// directory, which is renamed once the job has been created.  It returns the name of the file and
+// the number of records saved.
+func takeSnapshot(ctx context.Context, opts ingestOptions, m *mapping.Mapping) (string, int, error) {
+	if app.ledger == nil {
+		return "", 0, errors.New("no user config directory available to keep the snapshot in")
+	}
+	if opts.input != nil {
+		return "", 0, errors.New("a snapshot can't be taken when reading a csv from stdin")
+	}
+
+	keyField := "Id"
+	if opts.operation == "upsert" {
+		keyField = opts.externalID
+	}
+	dr, err := app.sc.Describe(ctx, opts.object)
+	if err != nil {
+		return "", 0, fmt.Errorf("problem describing %s: %w", opts.object, err)
+	}
+	caseSensitive := true
+	if f := dr.FieldByName(keyField); f != nil && f.Type != "id" {
+		caseSensitive = f.CaseSensitive
+	}
+
+	src, err := openSource(opts, m)
+	if err != nil {
+		return "", 0, err
+	}
+	defer src.Close()
+	r := csv.NewReader(src)
+	r.Comma = opts.comma()
+	header, err := r.Read()
+	if err != nil {
+		return "", 0, fmt.Errorf("problem reading %s: %w", opts.file, err)
+	}
+	keyIdx := -1
+	var columns []string
+	for i, h := range header {
+		switch {
+		case strings.EqualFold(h, keyField):
+			keyIdx = i
+		case strings.EqualFold(h, "Id"):
+		case strings.Contains(h, ":"):
+			fmt.Fprintf(os.Stderr, "Warning: %s can't be saved in the snapshot, so won't be restored by a rollback\n", h)
+		default:
+			columns = append(columns, h)
+		}
+	}
+	if keyIdx < 0 {
+		return "", 0, fmt.Errorf("%s column is missing", keyField)
+	}
+
+	if err := os.MkdirAll(app.ledger.SnapshotDir(), 0700); err != nil {
+		return "", 0, err
+	}
+	f, err := os.CreateTemp(app.ledger.SnapshotDir(), "snapshot-*.csv")
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	complete := false
+	defer func() {
+		if !complete {
+			os.Remove(f.Name())
+		}
+	}()
+	w := csv.NewWriter(f)
+	if err := w.Write(append([]string{"Id"}, columns...)); err != nil {
+		return "", 0, err
+	}
+
+	saved := 0
+	seen := make(map[string]bool)
+	save := func(keys []string) error {
+		existing, err := queryExisting(ctx, opts.object, keyField, columns, keys, caseSensitive)
+		if err != nil {
+			return err
+		}
+		var records []map[string]string
+		for _, matches := range existing {
+			records = append(records, matches...)
+		}
+		sort.Slice(records, func(i, j int) bool { return records[i]["Id"] < records[j]["Id"] })
+		for _, values := range records {
+			// the same record may appear in more than one batch if the file repeats a key
+			if seen[values["Id"]] {
+				continue
+			}
+			seen[values["Id"]] = true
+			row := []string{values["Id"]}
+			for _, c := range columns {
+				row = append(row, values[c])
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+			saved++
+		}
+		return nil
+	}
+
+	var keys []string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", 0, fmt.Errorf("problem reading %s: %w", opts.file, err)
+		}
+		if record[keyIdx] == "" {
+			continue
+		}
+		keys = append(keys, record[keyIdx])
+		if len(keys) == existingBatchSize {
+			if err := save(keys); err != nil {
+				return "", 0, err
+			}
+			keys = nil
+		}
+	}
+	if len(keys) > 0 {
+		if err := save(keys); err != nil {
+			return "", 0, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", 0, err
+	}
+	complete = true
+	return f.Name(), saved, nil
+}
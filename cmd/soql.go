@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+	"github.com/darrenparkinson/sfcli/pkg/soql"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var soqlCmd = &cobra.Command{
+	Use:   "soql",
+	Short: "Work with SOQL queries without running them",
+}
+
+var soqlLintCmd = &cobra.Command{
+	Use:   "lint <file>...",
+	Short: "Check SOQL files for syntax errors, unknown fields, non-selective filters and missing limits",
+	Long: `Check SOQL files for syntax errors, unknown fields, non-selective filters and missing limits.
+
+Each file holds a single query, and - reads the query from stdin.  Fields are checked against the
+describe metadata for the objects, which is cached so that later runs, and runs with --offline,
+don't need to call salesforce.`,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{offlineAnnotation: "offline"},
+	Run:         soqlLint,
+}
+
+func init() {
+	rootCmd.AddCommand(soqlCmd)
+	soqlCmd.AddCommand(soqlLintCmd)
+
+	soqlLintCmd.Flags().Bool("offline", false, "Only use cached describe metadata, skipping field checks for objects that aren't cached")
+	viper.BindPFlag("soqlLintOffline", soqlLintCmd.Flags().Lookup("offline"))
+
+	soqlLintCmd.Flags().StringSlice("large-objects", nil, "Objects whose filters must be selective (default all objects)")
+	viper.BindPFlag("soqlLargeObjects", soqlLintCmd.Flags().Lookup("large-objects"))
+}
+
+func soqlLint(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	offline := viper.GetBool("soqlLintOffline")
+	describes := make(map[string]*salesforce.DescribeResponse)
+	describe := func(object string) (*salesforce.DescribeResponse, error) {
+		key := strings.ToLower(object)
+		if dr, ok := describes[key]; ok {
+			return dr, nil
+		}
+		dr, err := cachedDescribe(ctx, object, offline)
+		if err != nil {
+			return nil, err
+		}
+		describes[key] = dr
+		return dr, nil
+	}
+
+	errors, warnings := 0, 0
+	for _, name := range args {
+		statement, err := readStatement(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err)
+			os.Exit(1)
+		}
+		opts := soql.LintOptions{Describe: describe, LargeObjects: viper.GetStringSlice("soqlLargeObjects")}
+		for _, issue := range soql.Lint(statement, opts) {
+			// offline, objects that haven't been cached are skipped rather than reported
+			if offline && issue.Rule == "unknown-object" {
+				continue
+			}
+			fmt.Printf("%s:%d:%d: %s: %s (%s)\n", name, issue.Pos.Line, issue.Pos.Column, issue.Severity, issue.Message, issue.Rule)
+			if issue.Severity == soql.SeverityError {
+				errors++
+			} else {
+				warnings++
+			}
+		}
+	}
+	if errors > 0 || warnings > 0 {
+		fmt.Printf("%d errors, %d warnings\n", errors, warnings)
+	}
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// readStatement reads a query from the named file, or stdin for -, without a trailing semicolon
+func readStatement(name string) (string, error) {
+	var b []byte
+	var err error
+	if name == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return "", err
+	}
+	// only trailing space is trimmed so that positions in issues match the file
+	return strings.TrimSuffix(strings.TrimRightFunc(string(b), unicode.IsSpace), ";"), nil
+}
+
+// expandFields expands FIELDS(ALL), FIELDS(STANDARD) and FIELDS(CUSTOM) into the fields of the object,
+// since the Bulk API doesn't accept them and the REST API only returns 200 records with them.  Queries
+// that can't be parsed are returned unchanged for salesforce to report on.
+func expandFields(ctx context.Context, sc *salesforce.Client, query string) (string, error) {
+	q, err := soql.Parse(query)
+	if err != nil || !q.UsesFields() {
+		return query, nil
+	}
+	dr, err := sc.Describe(ctx, q.Object)
+	if err != nil {
+		return "", fmt.Errorf("problem describing %s to expand FIELDS(): %w", q.Object, err)
+	}
+	if err := q.ExpandFields(dr); err != nil {
+		return "", err
+	}
+	return q.String(), nil
+}
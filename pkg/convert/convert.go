@@ -0,0 +1,90 @@
+// Package convert turns the files we are given into the CSV that the Bulk 2.0 API requires.
+package convert
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format represents the format of a source file
+type Format string
+
+// Supported formats
+const (
+	FormatCSV    Format = "csv"
+	FormatJSON   Format = "json"   // an array of objects, or a single object
+	FormatNDJSON Format = "ndjson" // one object per line
+	FormatXLSX   Format = "xlsx"   // Excel workbook
+)
+
+// Source is the input for a conversion.  Both *os.File and *bytes.Reader implement it.
+// JSON is read twice, first to find the columns, and workbooks require random access.
+type Source interface {
+	io.Reader
+	io.Seeker
+	io.ReaderAt
+}
+
+// ParseFormat returns the format for the given name, e.g. csv, json, ndjson or xlsx
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatCSV, FormatJSON, FormatNDJSON, FormatXLSX:
+		return f, nil
+	case "jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("convert: unsupported format %s", name)
+}
+
+// DetectFormat returns the format of a file based on its extension.  Unrecognised extensions are treated as CSV.
+func DetectFormat(filename string) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".xlsx", ".xlsm":
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+// ToCSV converts src from the given format and writes it to w as a comma delimited CSV.
+// The sheet is only used for workbooks and can be a sheet name or number, or empty for the first sheet.
+func ToCSV(w io.Writer, src Source, format Format, sheet string) error {
+	switch format {
+	case FormatCSV:
+		_, err := io.Copy(w, src)
+		return err
+	case FormatJSON, FormatNDJSON:
+		return jsonToCSV(w, src)
+	case FormatXLSX:
+		return xlsxToCSV(w, src, sheet)
+	}
+	return fmt.Errorf("convert: unsupported format %s", format)
+}
+
+// NewReader returns a reader of the converted CSV.  The conversion happens as the data is read,
+// and closing the returned reader also closes src.
+func NewReader(src Source, format Format, sheet string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(ToCSV(pw, src, format, sheet))
+	}()
+	return &convertedReader{PipeReader: pr, source: src}
+}
+
+type convertedReader struct {
+	*io.PipeReader
+	source Source
+}
+
+func (r *convertedReader) Close() error {
+	r.PipeReader.Close()
+	if c, ok := r.source.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package convert
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// nullValue is written for a JSON null, which clears the field in a bulk load rather than leaving
+// it unchanged as an empty value does.  A null related record is dropped by dropParents instead.
+const nullValue = "#N/A"
+
+// row is a flattened JSON object, e.g. {"Owner":{"Email":"x"}} becomes Owner.Email=x
+type row struct {
+	keys   []string
+	values map[string]string
+}
+
+func (r *row) set(key, value string) {
+	if _, ok := r.values[key]; !ok {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = value
+}
+
+// jsonToCSV reads the objects twice, once to find every column and again to write the rows
+func jsonToCSV(w io.Writer, src Source) error {
+	var header []string
+	columns := make(map[string]bool)
+	err := eachObject(src, func(r *row) error {
+		for _, k := range r.keys {
+			if !columns[k] {
+				columns[k] = true
+				header = append(header, k)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	header = dropParents(header)
+	if len(header) == 0 {
+		return errors.New("convert: no objects found")
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	err = eachObject(src, func(r *row) error {
+		for i, h := range header {
+			record[i] = r.values[h]
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// dropParents removes the columns of related records that are null in some objects, e.g. Account
+// from {"Account": null}, when other objects have fields of the record such as Account.ERP_Id__c.
+// Those objects are left with empty values for the fields, which leaves the relationship unchanged.
+func dropParents(header []string) []string {
+	parents := make(map[string]bool)
+	for _, h := range header {
+		for i := 0; i < len(h); i++ {
+			if h[i] == '.' {
+				parents[h[:i]] = true
+			}
+		}
+	}
+	columns := header[:0]
+	for _, h := range header {
+		if !parents[h] {
+			columns = append(columns, h)
+		}
+	}
+	return columns
+}
+
+// eachObject calls fn with every object found, whether they are in an array or one after another
+func eachObject(r io.Reader, fn func(*row) error) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	n := 0
+	object := func() error {
+		n++
+		r := &row{values: make(map[string]string)}
+		if err := readObject(dec, "", r); err != nil {
+			return fmt.Errorf("convert: object %d: %w", n, err)
+		}
+		return fn(r)
+	}
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("convert: %w", err)
+		}
+		switch t {
+		case json.Delim('{'):
+			if err := object(); err != nil {
+				return err
+			}
+		case json.Delim('['):
+			for dec.More() {
+				t, err := dec.Token()
+				if err != nil {
+					return fmt.Errorf("convert: %w", err)
+				}
+				if t != json.Delim('{') {
+					return fmt.Errorf("convert: expected an object, found %v", t)
+				}
+				if err := object(); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return fmt.Errorf("convert: %w", err)
+			}
+		default:
+			return fmt.Errorf("convert: expected an object, found %v", t)
+		}
+	}
+}
+
+// readObject reads the fields of an object whose opening brace has already been read,
+// adding them to the row with the given prefix
+func readObject(dec *json.Decoder, prefix string, r *row) error {
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := t.(string)
+		// records exported from salesforce include their type and url as attributes
+		if key == "attributes" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := readValue(dec, prefix+key, r); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// readValue reads the next value, flattening objects and joining arrays with a semicolon as for multi-select picklists
+func readValue(dec *json.Decoder, name string, r *row) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch v := t.(type) {
+	case json.Delim:
+		if v == '{' {
+			return readObject(dec, name+".", r)
+		}
+		var values []string
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if t == nil {
+				continue
+			}
+			s, ok := scalar(t)
+			if !ok {
+				return fmt.Errorf("%s: arrays of objects are not supported", name)
+			}
+			values = append(values, s)
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		r.set(name, strings.Join(values, ";"))
+	case nil:
+		r.set(name, nullValue)
+	default:
+		s, _ := scalar(t)
+		r.set(name, s)
+	}
+	return nil
+}
+
+// scalar returns the CSV representation of a JSON string, number, boolean or null
+func scalar(t json.Token) (string, bool) {
+	switch v := t.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "", true
+	}
+	return "", false
+}
@@ -0,0 +1,107 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONToCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		json   string
+		want   string
+	}{
+		{
+			name:   "array",
+			format: FormatJSON,
+			json:   `[{"LastName":"Smith","Age":42},{"LastName":"Jones","Active":true}]`,
+			want:   "LastName,Age,Active\nSmith,42,\nJones,,true\n",
+		},
+		{
+			name:   "single object",
+			format: FormatJSON,
+			json:   `{"LastName":"Smith"}`,
+			want:   "LastName\nSmith\n",
+		},
+		{
+			name:   "ndjson",
+			format: FormatNDJSON,
+			json:   "{\"LastName\":\"Smith\"}\n{\"LastName\":\"Jones\",\"Email\":\"j@example.com\"}\n",
+			want:   "LastName,Email\nSmith,\nJones,j@example.com\n",
+		},
+		{
+			name:   "nested",
+			format: FormatJSON,
+			json:   `[{"attributes":{"type":"Contact"},"LastName":"Smith","Account":{"attributes":{"type":"Account"},"ERP__c":"A1","Owner":{"Email":"o@example.com"}}}]`,
+			want:   "LastName,Account.ERP__c,Account.Owner.Email\nSmith,A1,o@example.com\n",
+		},
+		{
+			name:   "arrays",
+			format: FormatJSON,
+			json:   `[{"Region__c":["EMEA","APAC",null],"Scores":[1,2.5]},{"Region__c":[]}]`,
+			want:   "Region__c,Scores\nEMEA;APAC,1;2.5\n,\n",
+		},
+		{
+			name:   "null scalars",
+			format: FormatJSON,
+			json:   `[{"LastName":"Smith","Email":null}]`,
+			want:   "LastName,Email\nSmith,#N/A\n",
+		},
+		{
+			name:   "null parents",
+			format: FormatJSON,
+			json:   `[{"LastName":"Smith","Account":null},{"LastName":"Jones","Account":{"ERP__c":"A1","Owner":null}},{"LastName":"Brown","Account":{"Owner":{"Email":"o@example.com"}}}]`,
+			want:   "LastName,Account.ERP__c,Account.Owner.Email\nSmith,,\nJones,A1,\nBrown,,o@example.com\n",
+		},
+		{
+			name:   "quoting",
+			format: FormatNDJSON,
+			json:   `{"Description":"a, \"b\"\nc"}`,
+			want:   "Description\n\"a, \"\"b\"\"\nc\"\n",
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := ToCSV(&b, strings.NewReader(tt.json), tt.format, ""); err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJSONToCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"empty", "", "no objects found"},
+		{"empty array", "[]", "no objects found"},
+		{"not an object", `["a"]`, "expected an object"},
+		{"array of objects", `[{"Tags":[{"Name":"x"}]}]`, "arrays of objects are not supported"},
+		{"invalid", `[{"LastName":}]`, "object 1"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		err := ToCSV(&b, strings.NewReader(tt.json), FormatJSON, "")
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDropParents(t *testing.T) {
+	got := strings.Join(dropParents([]string{"Account", "Name", "Account.Owner", "Account.Owner.Email", "Accounts"}), ",")
+	if want := "Name,Account.Owner.Email,Accounts"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,218 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encoding names accepted by NewDecoder
+const (
+	EncodingAuto        = "auto"
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16       = "utf-16"
+	EncodingWindows1252 = "windows-1252"
+	EncodingISO88591    = "iso-8859-1"
+)
+
+// sniffSize is the amount of data examined when detecting the encoding, delimiter and line ending
+const sniffSize = 64 * 1024
+
+// Delimiters maps the names used on the command line to the names used by the bulk API
+var Delimiters = map[string]string{
+	"comma":     "COMMA",
+	"tab":       "TAB",
+	"semicolon": "SEMICOLON",
+	"pipe":      "PIPE",
+	"caret":     "CARET",
+	"backquote": "BACKQUOTE",
+}
+
+// delimiterOrder is the order in which delimiters are preferred when they are equally likely
+var delimiterOrder = []struct {
+	name string
+	char byte
+}{
+	{"COMMA", ','}, {"TAB", '\t'}, {"SEMICOLON", ';'}, {"PIPE", '|'}, {"CARET", '^'}, {"BACKQUOTE", '`'},
+}
+
+// NewDecoder returns a reader that converts r from the named encoding into the UTF-8 that salesforce
+// requires, removing any byte order mark.  With EncodingAuto, or an empty name, the encoding is detected
+// from the byte order mark, or from the zero bytes of UTF-16 text without one, falling back to UTF-8 if
+// the start of the data is valid UTF-8 and Windows-1252 if not.
+func NewDecoder(r io.Reader, name string) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+	sample, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	var enc encoding.Encoding
+	switch strings.ToLower(name) {
+	case "", EncodingAuto:
+		switch {
+		case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+			enc = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+		case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+			enc = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+		case bytes.IndexByte(sample, 0) >= 0:
+			endianness, ok := utf16Endianness(sample)
+			if !ok {
+				return nil, fmt.Errorf("convert: unable to detect the encoding of data containing NUL bytes, specify it instead")
+			}
+			enc = unicode.UTF16(endianness, unicode.IgnoreBOM)
+		case validUTF8(sample):
+			enc = unicode.UTF8BOM
+		default:
+			enc = charmap.Windows1252
+		}
+	case EncodingUTF8, "utf8":
+		enc = unicode.UTF8BOM
+	case EncodingUTF16, "utf16":
+		// without a byte order mark, windows tools write little endian
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case EncodingWindows1252, "cp1252":
+		enc = charmap.Windows1252
+	case EncodingISO88591, "latin1":
+		enc = charmap.ISO8859_1
+	default:
+		return nil, fmt.Errorf("convert: unsupported encoding %s", name)
+	}
+	return transform.NewReader(br, enc.NewDecoder()), nil
+}
+
+// utf16Endianness detects UTF-16 without a byte order mark from the zero bytes of the characters
+// below U+0100, which make up most of a CSV file.  They come after each character in little endian
+// text and before it in big endian.
+func utf16Endianness(sample []byte) (unicode.Endianness, bool) {
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	var even, odd int
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	pairs := len(sample) / 2
+	switch {
+	case odd > pairs/2 && even <= odd/10:
+		return unicode.LittleEndian, true
+	case even > pairs/2 && odd <= even/10:
+		return unicode.BigEndian, true
+	}
+	return unicode.LittleEndian, false
+}
+
+// validUTF8 reports whether the sample is valid UTF-8, ignoring a rune that has been cut off at the end
+func validUTF8(sample []byte) bool {
+	for i := 0; i < utf8.UTFMax && len(sample) > 0; i++ {
+		if utf8.Valid(sample) {
+			return true
+		}
+		if r, _ := utf8.DecodeLastRune(sample); r != utf8.RuneError {
+			return false
+		}
+		sample = sample[:len(sample)-1]
+	}
+	return utf8.Valid(sample)
+}
+
+// Sniff examines the start of a UTF-8 CSV file and returns the bulk API names of the most
+// likely column delimiter, e.g. COMMA or TAB, and line ending, either LF or CRLF.
+func Sniff(sample []byte) (delimiter, lineEnding string) {
+	lines := splitLines(sample, 20)
+	lineEnding = "LF"
+	if len(lines) > 0 && bytes.HasSuffix(lines[0], []byte("\r")) {
+		lineEnding = "CRLF"
+	}
+
+	// the delimiter is the character that appears the same number of times in the most lines,
+	// preferring the one that appears most often in the header
+	delimiter = "COMMA"
+	bestLines, bestCount := 0, 0
+	for _, d := range delimiterOrder {
+		if len(lines) == 0 {
+			break
+		}
+		header := countOutsideQuotes(lines[0], d.char)
+		if header == 0 {
+			continue
+		}
+		consistent := 0
+		for _, line := range lines {
+			if countOutsideQuotes(line, d.char) == header {
+				consistent++
+			}
+		}
+		if consistent > bestLines || (consistent == bestLines && header > bestCount) {
+			delimiter, bestLines, bestCount = d.name, consistent, header
+		}
+	}
+	return delimiter, lineEnding
+}
+
+// splitLines returns up to max complete lines, ignoring line breaks within quoted values
+func splitLines(sample []byte, max int) [][]byte {
+	var lines [][]byte
+	inQuotes := false
+	start := 0
+	for i, c := range sample {
+		switch c {
+		case '"':
+			inQuotes = !inQuotes
+		case '\n':
+			if inQuotes {
+				continue
+			}
+			lines = append(lines, sample[start:i])
+			start = i + 1
+			if len(lines) == max {
+				return lines
+			}
+		}
+	}
+	if start < len(sample) && len(lines) == 0 {
+		lines = append(lines, sample[start:])
+	}
+	return lines
+}
+
+func countOutsideQuotes(line []byte, c byte) int {
+	n := 0
+	inQuotes := false
+	for _, b := range line {
+		switch {
+		case b == '"':
+			inQuotes = !inQuotes
+		case b == c && !inQuotes:
+			n++
+		}
+	}
+	return n
+}
+
+// SniffReader returns the delimiter and line ending of the data in r as for Sniff, along with
+// a reader that returns all of the data, including the part that was examined.
+func SniffReader(r io.Reader) (delimiter, lineEnding string, all io.Reader, err error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+	sample, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", "", nil, err
+	}
+	delimiter, lineEnding = Sniff(sample)
+	return delimiter, lineEnding, br, nil
+}
@@ -0,0 +1,90 @@
+package convert
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// utf16 encodes s as UTF-16 without a byte order mark
+func utf16(s string, bigEndian bool) []byte {
+	var b []byte
+	for _, r := range s {
+		if bigEndian {
+			b = append(b, byte(r>>8), byte(r))
+		} else {
+			b = append(b, byte(r), byte(r>>8))
+		}
+	}
+	return b
+}
+
+func TestNewDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		data     []byte
+		want     string
+	}{
+		{"utf-8", "", []byte("Name\nZoë\n"), "Name\nZoë\n"},
+		{"utf-8 bom", "", append([]byte{0xEF, 0xBB, 0xBF}, "Name\nZoë\n"...), "Name\nZoë\n"},
+		{"utf-16le bom", "", append([]byte{0xFF, 0xFE}, utf16("Name\nZoë\n", false)...), "Name\nZoë\n"},
+		{"utf-16be bom", "auto", append([]byte{0xFE, 0xFF}, utf16("Name\nZoë\n", true)...), "Name\nZoë\n"},
+		{"utf-16le without bom", "", utf16("Name,Email\nZoë,z@example.com\n", false), "Name,Email\nZoë,z@example.com\n"},
+		{"utf-16be without bom", "", utf16("Name,Email\nZoë,z@example.com\n", true), "Name,Email\nZoë,z@example.com\n"},
+		{"windows-1252 fallback", "", []byte("Name\nZo\xeb \x80\n"), "Name\nZoë €\n"},
+		{"utf-8 cut off", "", []byte("Zo\xc3"), "Zo\ufffd"},
+		{"windows-1252", "cp1252", []byte("\x93quoted\x94"), "“quoted”"},
+		{"iso-8859-1", "latin1", []byte("Zo\xeb"), "Zoë"},
+		{"utf-16 named", "utf-16", utf16("Name", false), "Name"},
+	}
+	for _, tt := range tests {
+		r, err := NewDecoder(bytes.NewReader(tt.data), tt.encoding)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if got := string(b); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewDecoderErrors(t *testing.T) {
+	if _, err := NewDecoder(strings.NewReader("Name"), "ebcdic"); err == nil || !strings.Contains(err.Error(), "unsupported encoding") {
+		t.Errorf("unsupported encoding: got %v", err)
+	}
+	if _, err := NewDecoder(bytes.NewReader([]byte("a\x00\x00\x00b\x00c")), ""); err == nil || !strings.Contains(err.Error(), "unable to detect") {
+		t.Errorf("NUL bytes: got %v", err)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		delimiter  string
+		lineEnding string
+	}{
+		{"comma", "Name,Email\na,b\nc,d\n", "COMMA", "LF"},
+		{"crlf", "Name,Email\r\na,b\r\n", "COMMA", "CRLF"},
+		{"tab", "Name\tEmail\na\tb\n", "TAB", "LF"},
+		{"semicolon with commas in values", "Name;Amount\na;1,5\nb;2,25\n", "SEMICOLON", "LF"},
+		{"pipe", "Name|Email|Phone\na|b|c\n", "PIPE", "LF"},
+		{"quoted delimiters", "Name;Notes\na;\"x,y,z\"\nb;\"1,2\n3\"\n", "SEMICOLON", "LF"},
+		{"single column", "Name\na\nb\n", "COMMA", "LF"},
+		{"empty", "", "COMMA", "LF"},
+	}
+	for _, tt := range tests {
+		delimiter, lineEnding := Sniff([]byte(tt.data))
+		if delimiter != tt.delimiter || lineEnding != tt.lineEnding {
+			t.Errorf("%s: got %s %s, want %s %s", tt.name, delimiter, lineEnding, tt.delimiter, tt.lineEnding)
+		}
+	}
+}
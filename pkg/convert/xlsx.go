@@ -0,0 +1,416 @@
+package convert
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// workbook holds the parts of an xlsx file needed to read a sheet
+type workbook struct {
+	zr         *zip.Reader
+	sheets     []sheetInfo
+	strings    []string
+	dateStyles map[int]bool
+	date1904   bool
+	files      map[string]*zip.File
+}
+
+type sheetInfo struct {
+	Name string
+	Path string
+}
+
+// Sheets returns the names of the sheets in the workbook
+func Sheets(src Source) ([]string, error) {
+	wb, err := openWorkbook(src)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(wb.sheets))
+	for i, s := range wb.sheets {
+		names[i] = s.Name
+	}
+	return names, nil
+}
+
+func xlsxToCSV(w io.Writer, src Source, sheet string) error {
+	wb, err := openWorkbook(src)
+	if err != nil {
+		return err
+	}
+	s, err := wb.sheet(sheet)
+	if err != nil {
+		return err
+	}
+	if err := wb.readSharedStrings(); err != nil {
+		return err
+	}
+	if err := wb.readStyles(); err != nil {
+		return err
+	}
+	return wb.writeSheet(w, s)
+}
+
+func openWorkbook(src Source) (*workbook, error) {
+	size, err := src.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(src, size)
+	if err != nil {
+		return nil, fmt.Errorf("convert: not a valid xlsx workbook: %w", err)
+	}
+	wb := &workbook{zr: zr, files: make(map[string]*zip.File)}
+	for _, f := range zr.File {
+		wb.files[f.Name] = f
+	}
+
+	var doc struct {
+		WorkbookPr struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := wb.decode("xl/workbook.xml", &doc); err != nil {
+		return nil, err
+	}
+	wb.date1904 = doc.WorkbookPr.Date1904 == "1" || doc.WorkbookPr.Date1904 == "true"
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := wb.decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string)
+	for _, r := range rels.Relationships {
+		target := r.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[r.ID] = target
+	}
+	for _, s := range doc.Sheets {
+		wb.sheets = append(wb.sheets, sheetInfo{Name: s.Name, Path: targets[s.RID]})
+	}
+	if len(wb.sheets) == 0 {
+		return nil, errors.New("convert: workbook has no sheets")
+	}
+	return wb, nil
+}
+
+// sheet finds a sheet by name, or by number starting at 1.  An empty selector returns the first sheet.
+func (wb *workbook) sheet(selector string) (sheetInfo, error) {
+	if selector == "" {
+		return wb.sheets[0], nil
+	}
+	for _, s := range wb.sheets {
+		if strings.EqualFold(s.Name, selector) {
+			return s, nil
+		}
+	}
+	if n, err := strconv.Atoi(selector); err == nil && n >= 1 && n <= len(wb.sheets) {
+		return wb.sheets[n-1], nil
+	}
+	return sheetInfo{}, fmt.Errorf("convert: sheet %s not found in workbook", selector)
+}
+
+func (wb *workbook) open(name string) (io.ReadCloser, error) {
+	f, ok := wb.files[name]
+	if !ok {
+		return nil, nil
+	}
+	return f.Open()
+}
+
+func (wb *workbook) decode(name string, v interface{}) error {
+	rc, err := wb.open(name)
+	if err != nil {
+		return err
+	}
+	if rc == nil {
+		return fmt.Errorf("convert: %s missing from workbook", name)
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// readSharedStrings loads the string table, joining the runs of rich text strings
+func (wb *workbook) readSharedStrings() error {
+	rc, err := wb.open("xl/sharedStrings.xml")
+	if err != nil || rc == nil {
+		return err
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(rc)
+	var current strings.Builder
+	inText, inPhonetic := false, false
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("convert: reading shared strings: %w", err)
+		}
+		switch el := t.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				inPhonetic = true
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "si":
+				wb.strings = append(wb.strings, current.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			}
+		case xml.CharData:
+			if inText && !inPhonetic {
+				current.Write(el)
+			}
+		}
+	}
+}
+
+// readStyles finds the cell styles that format numbers as dates
+func (wb *workbook) readStyles() error {
+	wb.dateStyles = make(map[int]bool)
+	var doc struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if _, ok := wb.files["xl/styles.xml"]; !ok {
+		return nil
+	}
+	if err := wb.decode("xl/styles.xml", &doc); err != nil {
+		return fmt.Errorf("convert: reading styles: %w", err)
+	}
+	custom := make(map[int]bool)
+	for _, f := range doc.NumFmts {
+		custom[f.ID] = isDateFormat(f.Code)
+	}
+	for i, xf := range doc.CellXfs {
+		id := xf.NumFmtID
+		if (id >= 14 && id <= 22) || (id >= 45 && id <= 47) || custom[id] {
+			wb.dateStyles[i] = true
+		}
+	}
+	return nil
+}
+
+// isDateFormat reports whether a custom number format displays a date or time
+func isDateFormat(code string) bool {
+	inQuote := false
+	for i := 0; i < len(code); i++ {
+		switch c := code[i]; {
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '\\':
+			i++
+		case c == '[':
+			// skip colours and conditions such as [Red] or [>100], but not elapsed time such as [h]
+			end := strings.IndexByte(code[i:], ']')
+			if end < 0 {
+				return false
+			}
+			if inner := strings.ToLower(code[i+1 : i+end]); inner == "h" || inner == "m" || inner == "s" || inner == "hh" || inner == "mm" || inner == "ss" {
+				return true
+			}
+			i += end
+		case strings.IndexByte("dmyhsDMYHS", c) >= 0:
+			return true
+		}
+	}
+	return false
+}
+
+// writeSheet streams the rows of the sheet to w.  The first non-empty row is the header,
+// and subsequent rows are padded or truncated to the same number of columns.
+func (wb *workbook) writeSheet(w io.Writer, s sheetInfo) error {
+	rc, err := wb.open(s.Path)
+	if err != nil {
+		return err
+	}
+	if rc == nil {
+		return fmt.Errorf("convert: sheet %s missing from workbook", s.Name)
+	}
+	defer rc.Close()
+
+	cw := csv.NewWriter(w)
+	dec := xml.NewDecoder(rc)
+	width := 0
+	var record []string
+	var cellRef, cellType, cellValue string
+	cellStyle := 0
+	inValue := false
+	col := 0
+
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("convert: reading sheet %s: %w", s.Name, err)
+		}
+		switch el := t.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "row":
+				record = record[:0]
+				col = 0
+			case "c":
+				cellRef, cellType, cellValue, cellStyle = "", "", "", 0
+				for _, a := range el.Attr {
+					switch a.Name.Local {
+					case "r":
+						cellRef = a.Value
+					case "t":
+						cellType = a.Value
+					case "s":
+						cellStyle, _ = strconv.Atoi(a.Value)
+					}
+				}
+			case "v", "t":
+				inValue = true
+			}
+		case xml.CharData:
+			if inValue {
+				cellValue += string(el)
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				if cellRef != "" {
+					if idx, ok := columnIndex(cellRef); ok {
+						col = idx
+					}
+				}
+				for len(record) < col {
+					record = append(record, "")
+				}
+				v, err := wb.cellValue(cellType, cellValue, cellStyle)
+				if err != nil {
+					return fmt.Errorf("convert: sheet %s cell %s: %w", s.Name, cellRef, err)
+				}
+				record = append(record, v)
+				col++
+			case "row":
+				if isBlank(record) {
+					continue
+				}
+				if width == 0 {
+					for len(record) > 0 && record[len(record)-1] == "" {
+						record = record[:len(record)-1]
+					}
+					width = len(record)
+				}
+				for len(record) < width {
+					record = append(record, "")
+				}
+				if err := cw.Write(record[:width]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if width == 0 {
+		return fmt.Errorf("convert: sheet %s is empty", s.Name)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// cellValue converts the raw value of a cell based on its type and style
+func (wb *workbook) cellValue(cellType, value string, style int) (string, error) {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(value)
+		if err != nil || i < 0 || i >= len(wb.strings) {
+			return "", fmt.Errorf("invalid shared string %q", value)
+		}
+		return wb.strings[i], nil
+	case "b":
+		return strconv.FormatBool(value == "1"), nil
+	case "str", "inlineStr", "e":
+		return value, nil
+	}
+	if value == "" || !wb.dateStyles[style] {
+		return value, nil
+	}
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value, nil
+	}
+	return wb.serialToTime(serial), nil
+}
+
+// serialToTime converts an Excel serial date into an ISO 8601 date, or date time if it has a time component
+func (wb *workbook) serialToTime(serial float64) string {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if wb.date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days := math.Floor(serial)
+	ms := math.Round((serial - days) * 24 * 60 * 60 * 1000)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(ms) * time.Millisecond)
+	if ms == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02T15:04:05.000Z")
+}
+
+// columnIndex returns the zero based column of a cell reference such as AB12
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
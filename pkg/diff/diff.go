@@ -0,0 +1,193 @@
+// Package diff compares the records in a bulk load with the records that already exist in
+// the org, so that the effect of a load can be seen before it is run.
+package diff
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status of a record in the load compared to the org
+type Status string
+
+// Statuses returned by Compare
+const (
+	StatusNew       Status = "new"       // no existing record matches, so it will be inserted
+	StatusChanged   Status = "changed"   // an existing record will be updated with different values
+	StatusUnchanged Status = "unchanged" // an existing record already has the same values
+	StatusConflict  Status = "conflict"  // more than one existing record matches, so the record will fail
+)
+
+// nullValue is the value used in bulk CSV files to set a field to null
+const nullValue = "#N/A"
+
+// Change is a single field whose value will change
+type Change struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Result is the comparison of a single record in the load with the org
+type Result struct {
+	Row     int      `json:"row"` // 1 is the first record after the header
+	Key     string   `json:"key"`
+	ID      string   `json:"id,omitempty"`
+	Status  Status   `json:"status"`
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Compare compares a record in the load with the existing records that have the same key.  Only the
+// fields in columns are compared, and types gives the salesforce field type of each column so that values
+// such as numbers and dates are compared by value.  existing holds the field values of each matching record
+// keyed by column name.  Empty values in the load leave the field unchanged, as they do in salesforce.
+func Compare(row int, key string, columns []string, types []string, record []string, existing []map[string]string) Result {
+	r := Result{Row: row, Key: key}
+	switch len(existing) {
+	case 0:
+		r.Status = StatusNew
+		return r
+	case 1:
+	default:
+		r.Status = StatusConflict
+		return r
+	}
+	before := existing[0]
+	r.ID = before["Id"]
+	for i, column := range columns {
+		after := record[i]
+		if after == "" {
+			continue
+		}
+		if after == nullValue {
+			after = ""
+		}
+		if !Equal(types[i], before[column], after) {
+			r.Changes = append(r.Changes, Change{Field: column, Before: before[column], After: after})
+		}
+	}
+	r.Status = StatusUnchanged
+	if len(r.Changes) > 0 {
+		r.Status = StatusChanged
+	}
+	return r
+}
+
+var dateTimeLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05.000Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05.000Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.000",
+}
+
+// Equal reports whether two values of a field of the given salesforce type are the same
+func Equal(fieldType, a, b string) bool {
+	if a == b {
+		return true
+	}
+	switch fieldType {
+	case "boolean":
+		return strings.EqualFold(a, b)
+	case "int", "double", "currency", "percent", "long":
+		x, errx := strconv.ParseFloat(a, 64)
+		y, erry := strconv.ParseFloat(b, 64)
+		return errx == nil && erry == nil && x == y
+	case "datetime":
+		x, okx := parseDateTime(a)
+		y, oky := parseDateTime(b)
+		return okx && oky && x.Equal(y)
+	case "id", "reference":
+		// 15 and 18 character ids refer to the same record
+		return len(a) >= 15 && len(b) >= 15 && a[:15] == b[:15]
+	}
+	return false
+}
+
+func parseDateTime(value string) (time.Time, bool) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Writer writes the results of a comparison
+type Writer interface {
+	Write(r Result) error
+	Close() error
+}
+
+// NewCSVWriter returns a writer that writes a line for each changed field, and a single
+// line for records that are new, unchanged or in conflict
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) Write(r Result) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write([]string{"Row", "Key", "Id", "Status", "Field", "Before", "After"}); err != nil {
+			return err
+		}
+	}
+	row := strconv.Itoa(r.Row)
+	if len(r.Changes) == 0 {
+		return c.w.Write([]string{row, r.Key, r.ID, string(r.Status), "", "", ""})
+	}
+	for _, ch := range r.Changes {
+		if err := c.w.Write([]string{row, r.Key, r.ID, string(r.Status), ch.Field, ch.Before, ch.After}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// NewJSONWriter returns a writer that writes the results as a JSON array
+func NewJSONWriter(w io.Writer) Writer {
+	return &jsonWriter{w: w}
+}
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(r Result) error {
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "[\n  "
+	}
+	j.count++
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(j.w, sep+string(b))
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
@@ -0,0 +1,136 @@
+package diff
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		a, b      string
+		want      bool
+	}{
+		{"string", "Acme", "Acme", true},
+		{"string", "Acme", "acme", false},
+		{"string", "", "", true},
+		{"boolean", "true", "TRUE", true},
+		{"boolean", "false", "true", false},
+		{"double", "1000", "1000.00", true},
+		{"currency", "1e3", "1000", true},
+		{"int", "5", "6", false},
+		{"percent", "", "0", false},
+		{"double", "abc", "abc ", false},
+		{"datetime", "2021-03-04T05:06:07.000+0000", "2021-03-04T05:06:07Z", true},
+		{"datetime", "2021-03-04T06:06:07+01:00", "2021-03-04T05:06:07.000Z", true},
+		{"datetime", "2021-03-04T05:06:07", "2021-03-04T05:06:07.000Z", true},
+		{"datetime", "2021-03-04T05:06:07Z", "2021-03-04T05:06:08Z", false},
+		{"datetime", "2021-03-04", "2021-03-04T00:00:00Z", false},
+		{"reference", "001D000000IqhSLIAZ", "001D000000IqhSL", true},
+		{"id", "001D000000IqhSL", "001d000000iqhsl", false},
+		{"reference", "001D000000IqhSL", "", false},
+		{"date", "2021-03-04", "2021-3-4", false},
+	}
+	for _, tt := range tests {
+		if got := Equal(tt.fieldType, tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%s, %q, %q) = %v, want %v", tt.fieldType, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	columns := []string{"ERP_Id__c", "Name", "AnnualRevenue", "Phone"}
+	types := []string{"string", "string", "currency", "phone"}
+	existing := map[string]string{"Id": "001A", "ERP_Id__c": "A1", "Name": "Acme", "AnnualRevenue": "1000.0", "Phone": "555"}
+
+	tests := []struct {
+		name     string
+		record   []string
+		existing []map[string]string
+		want     Result
+	}{
+		{
+			name:   "new",
+			record: []string{"A1", "Acme", "1000", ""},
+			want:   Result{Row: 1, Key: "A1", Status: StatusNew},
+		},
+		{
+			name:     "conflict",
+			record:   []string{"A1", "Acme", "1000", ""},
+			existing: []map[string]string{existing, existing},
+			want:     Result{Row: 1, Key: "A1", Status: StatusConflict},
+		},
+		{
+			name:     "unchanged",
+			record:   []string{"A1", "Acme", "1000", ""},
+			existing: []map[string]string{existing},
+			want:     Result{Row: 1, Key: "A1", ID: "001A", Status: StatusUnchanged},
+		},
+		{
+			name:     "changed",
+			record:   []string{"A1", "Acme Ltd", "1000", "#N/A"},
+			existing: []map[string]string{existing},
+			want: Result{Row: 1, Key: "A1", ID: "001A", Status: StatusChanged, Changes: []Change{
+				{Field: "Name", Before: "Acme", After: "Acme Ltd"},
+				{Field: "Phone", Before: "555", After: ""},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		got := Compare(1, "A1", columns, types, tt.record, tt.existing)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriters(t *testing.T) {
+	results := []Result{
+		{Row: 1, Key: "A1", Status: StatusNew},
+		{Row: 2, Key: "A2", ID: "001B", Status: StatusChanged, Changes: []Change{{Field: "Name", Before: "a", After: "b, c"}, {Field: "Phone", Before: "1", After: ""}}},
+	}
+	tests := []struct {
+		name   string
+		writer func(*bytes.Buffer) Writer
+		want   string
+		empty  string
+	}{
+		{
+			name:   "csv",
+			writer: func(b *bytes.Buffer) Writer { return NewCSVWriter(b) },
+			want:   "Row,Key,Id,Status,Field,Before,After\n1,A1,,new,,,\n2,A2,001B,changed,Name,a,\"b, c\"\n2,A2,001B,changed,Phone,1,\n",
+			empty:  "",
+		},
+		{
+			name:   "json",
+			writer: func(b *bytes.Buffer) Writer { return NewJSONWriter(b) },
+			want: `[
+  {"row":1,"key":"A1","status":"new"},
+  {"row":2,"key":"A2","id":"001B","status":"changed","changes":[{"field":"Name","before":"a","after":"b, c"},{"field":"Phone","before":"1","after":""}]}
+]
+`,
+			empty: "[]\n",
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		w := tt.writer(&b)
+		for _, r := range results {
+			if err := w.Write(r); err != nil {
+				t.Fatalf("%s: %s", tt.name, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+
+		b.Reset()
+		if err := tt.writer(&b).Close(); err != nil || b.String() != tt.empty {
+			t.Errorf("%s: empty results gave %q, %v, want %q", tt.name, b.String(), err, tt.empty)
+		}
+	}
+}
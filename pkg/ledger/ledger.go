@@ -0,0 +1,202 @@
+// Package ledger keeps a local record of the bulk jobs created by sfcli so that
+// they can be traced after the terminal that created them has gone.
+package ledger
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a job is not present in the ledger.
+var ErrNotFound = errors.New("ledger: job not found")
+
+// Stages of an ingest job as it is loaded, so that a load that was interrupted can be resumed
+const (
+	StageCreated  = "created"  // the job has been created but the data may not have been uploaded
+	StageUploaded = "uploaded" // the data has been uploaded but the job has not been marked UploadComplete
+	StageStarted  = "started"  // the job has been marked UploadComplete and salesforce is processing it
+)
+
+// Entry represents a single job created by sfcli, with everything needed to run it again.
+type Entry struct {
+	ID         string `json:"id"`
+	ParentID   string `json:"parentId,omitempty"` // set when the job retries or re-runs another job
+	Org        string `json:"org"`                // base url of the org
+	Username   string `json:"username"`           // salesforce user that created the job
+	User       string `json:"user"`               // local user that ran sfcli
+	Object     string `json:"object"`
+	Operation  string `json:"operation"`
+	ExternalID string `json:"externalId,omitempty"`
+
+	Source    string `json:"source,omitempty"`   // absolute path of the file loaded, or a description of where the data came from
+	Checksum  string `json:"checksum,omitempty"` // sha256 of the file loaded
+	Format    string `json:"format,omitempty"`
+	Sheet     string `json:"sheet,omitempty"`
+	Delimiter string `json:"delimiter,omitempty"`
+	Encoding  string `json:"encoding,omitempty"`
+	CRLF      bool   `json:"crlf,omitempty"`
+	Mapping   string `json:"mapping,omitempty"`  // absolute path of the mapping file
+	Snapshot  string `json:"snapshot,omitempty"` // path of the values of the records before they were loaded
+
+	// Sync is set for loads made with the sObject collections API rather than a bulk job.  They have
+	// no job in salesforce, so their results are kept in the Results directory instead.
+	Sync    bool   `json:"sync,omitempty"`
+	Results string `json:"results,omitempty"`
+
+	Stage            string    `json:"stage,omitempty"`
+	State            string    `json:"state"`
+	RecordsProcessed int       `json:"recordsProcessed"`
+	RecordsFailed    int       `json:"recordsFailed"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// IsFile reports whether the entry was loaded from a file, and so can be run again
+func (e *Entry) IsFile() bool {
+	return filepath.IsAbs(e.Source)
+}
+
+// Ledger is an append only JSON lines file of entries.  When an entry is recorded
+// more than once, the last one written wins.
+type Ledger struct {
+	path string
+	mu   sync.Mutex // serialises writes from concurrent loads
+}
+
+// DefaultPath returns the default location of the ledger within the user config directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sfcli", "ledger.jsonl"), nil
+}
+
+// New returns a ledger stored at the given path.  The file is created on first write.
+func New(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Path returns the location of the ledger file.
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// SnapshotDir returns the directory that snapshots of records are kept in, next to the ledger.
+func (l *Ledger) SnapshotDir() string {
+	return filepath.Join(filepath.Dir(l.path), "snapshots")
+}
+
+// ResultsDir returns the directory that the results of synchronous loads are kept in, next to the ledger.
+func (l *Ledger) ResultsDir() string {
+	return filepath.Join(filepath.Dir(l.path), "results")
+}
+
+// SyncID returns an id for a synchronous load, which has no job id of its own
+func SyncID(t time.Time) string {
+	return "sync-" + t.UTC().Format("20060102T150405.000000")
+}
+
+// Record appends the entry to the ledger.
+func (l *Ledger) Record(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Entries returns the latest version of every entry in the order they were first recorded.
+func (l *Ledger) Entries() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	index := make(map[string]int)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		if i, ok := index[e.ID]; ok {
+			entries[i] = e
+			continue
+		}
+		index[e.ID] = len(entries)
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// Get returns the latest entry for the given job id.
+func (l *Ledger) Get(id string) (*Entry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return &e, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Children returns the entries recorded with the given job as their parent.
+func (l *Ledger) Children(id string) ([]Entry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var children []Entry
+	for _, e := range entries {
+		if e.ParentID == id {
+			children = append(children, e)
+		}
+	}
+	return children, nil
+}
+
+// Checksum returns the sha256 checksum of the named file
+func Checksum(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,353 @@
+// Package mapping renames, drops, sets and transforms the columns of a CSV file so that
+// source data can be loaded into salesforce without first editing it by hand.
+//
+// A mapping is usually loaded from a YAML file such as:
+//
+//	fields:
+//	  - target: LastName
+//	    source: SURNAME
+//	    transform: [trim]
+//	  - target: Name
+//	    concat: [FIRST_NAME, SURNAME]
+//	    separator: " "
+//	  - target: Birthdate
+//	    source: DOB
+//	    date: DD/MM/YYYY
+//	  - target: Industry
+//	    source: SECTOR
+//	    lookup:
+//	      FIN: Finance
+//	      TECH: Technology
+//	    default: Other
+//	  - target: LeadSource
+//	    value: ERP
+//	drop: [INTERNAL_REF]
+//
+// Columns that are not used as a source and are not dropped are passed through unchanged
+// unless passthrough is set to false.
+package mapping
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Mapping describes how to convert a source CSV into the columns required by salesforce
+type Mapping struct {
+	Fields      []Field  `yaml:"fields"`
+	Drop        []string `yaml:"drop,omitempty"`
+	Passthrough *bool    `yaml:"passthrough,omitempty"` // default is true
+}
+
+// Field describes a single output column.  Exactly one of Source, Concat or Value must be provided.
+type Field struct {
+	Target    string            `yaml:"target"`
+	Source    string            `yaml:"source,omitempty"`    // column to take the value from
+	Concat    []string          `yaml:"concat,omitempty"`    // columns to join together
+	Separator string            `yaml:"separator,omitempty"` // used between concatenated values
+	Value     *string           `yaml:"value,omitempty"`     // constant value
+	Transform []string          `yaml:"transform,omitempty"` // any of trim, upper and lower, applied in order
+	Lookup    map[string]string `yaml:"lookup,omitempty"`    // replaces values found in the table
+	Default   *string           `yaml:"default,omitempty"`   // used for values not found in the lookup table
+	Date      string            `yaml:"date,omitempty"`      // source format of a date, converted to YYYY-MM-DD
+	DateTime  string            `yaml:"datetime,omitempty"`  // source format of a date time, converted to ISO 8601
+	Timezone  string            `yaml:"timezone,omitempty"`  // location of date times without a zone, default is UTC
+}
+
+// Load reads and checks the mapping from the named YAML file
+func Load(name string) (*Mapping, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Parse reads and checks the mapping from YAML
+func Parse(b []byte) (*Mapping, error) {
+	var m Mapping
+	if err := yaml.UnmarshalStrict(b, &m); err != nil {
+		return nil, fmt.Errorf("mapping: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks that each field is complete and consistent
+func (m *Mapping) Validate() error {
+	targets := make(map[string]bool)
+	for i, f := range m.Fields {
+		if f.Target == "" {
+			return fmt.Errorf("mapping: field %d has no target", i+1)
+		}
+		if targets[strings.ToLower(f.Target)] {
+			return fmt.Errorf("mapping: duplicate target %s", f.Target)
+		}
+		targets[strings.ToLower(f.Target)] = true
+		sources := 0
+		if f.Source != "" {
+			sources++
+		}
+		if len(f.Concat) > 0 {
+			sources++
+		}
+		if f.Value != nil {
+			sources++
+		}
+		if sources != 1 {
+			return fmt.Errorf("mapping: %s must have exactly one of source, concat or value", f.Target)
+		}
+		for _, t := range f.Transform {
+			if _, ok := transforms[strings.ToLower(t)]; !ok {
+				return fmt.Errorf("mapping: %s has unknown transform %s", f.Target, t)
+			}
+		}
+		if f.Date != "" && f.DateTime != "" {
+			return fmt.Errorf("mapping: %s cannot have both date and datetime", f.Target)
+		}
+		if f.Timezone != "" {
+			if _, err := time.LoadLocation(f.Timezone); err != nil {
+				return fmt.Errorf("mapping: %s: %w", f.Target, err)
+			}
+		}
+	}
+	return nil
+}
+
+var transforms = map[string]func(string) string{
+	"trim":  strings.TrimSpace,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// column is a compiled output column
+type column struct {
+	field    *Field
+	sources  []int
+	location *time.Location
+	layout   string
+}
+
+// compile resolves the columns of the header and returns the output header and columns
+func (m *Mapping) compile(header []string) ([]string, []column, error) {
+	index := make(map[string]int)
+	for i, h := range header {
+		index[h] = i
+	}
+	lookup := func(target, name string) (int, error) {
+		i, ok := index[name]
+		if !ok {
+			return 0, fmt.Errorf("mapping: %s refers to column %s, which is not in the file", target, name)
+		}
+		return i, nil
+	}
+
+	var outHeader []string
+	var columns []column
+	used := make(map[int]bool)
+	for i := range m.Fields {
+		f := &m.Fields[i]
+		c := column{field: f, location: time.UTC}
+		names := f.Concat
+		if f.Source != "" {
+			names = []string{f.Source}
+		}
+		for _, name := range names {
+			idx, err := lookup(f.Target, name)
+			if err != nil {
+				return nil, nil, err
+			}
+			c.sources = append(c.sources, idx)
+			used[idx] = true
+		}
+		if f.Timezone != "" {
+			c.location, _ = time.LoadLocation(f.Timezone)
+		}
+		switch {
+		case f.Date != "":
+			c.layout = Layout(f.Date)
+		case f.DateTime != "":
+			c.layout = Layout(f.DateTime)
+		}
+		outHeader = append(outHeader, f.Target)
+		columns = append(columns, c)
+	}
+
+	for _, name := range m.Drop {
+		idx, err := lookup("drop", name)
+		if err != nil {
+			return nil, nil, err
+		}
+		used[idx] = true
+	}
+
+	if m.Passthrough == nil || *m.Passthrough {
+		for i, h := range header {
+			if used[i] {
+				continue
+			}
+			outHeader = append(outHeader, h)
+			columns = append(columns, column{sources: []int{i}})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, h := range outHeader {
+		if seen[strings.ToLower(h)] {
+			return nil, nil, fmt.Errorf("mapping: column %s would appear more than once in the output", h)
+		}
+		seen[strings.ToLower(h)] = true
+	}
+	return outHeader, columns, nil
+}
+
+// value returns the output value for the column
+func (c *column) value(record []string) (string, error) {
+	if c.field == nil {
+		return record[c.sources[0]], nil
+	}
+	f := c.field
+	var v string
+	switch {
+	case f.Value != nil:
+		v = *f.Value
+	case len(f.Concat) > 0:
+		parts := make([]string, 0, len(c.sources))
+		for _, i := range c.sources {
+			if record[i] != "" {
+				parts = append(parts, record[i])
+			}
+		}
+		v = strings.Join(parts, f.Separator)
+	default:
+		v = record[c.sources[0]]
+	}
+	for _, t := range f.Transform {
+		v = transforms[strings.ToLower(t)](v)
+	}
+	if f.Lookup != nil {
+		if lv, ok := f.Lookup[v]; ok {
+			v = lv
+		} else if f.Default != nil {
+			v = *f.Default
+		}
+	}
+	if c.layout != "" && v != "" {
+		t, err := time.ParseInLocation(c.layout, v, c.location)
+		if err != nil {
+			return "", fmt.Errorf("%s: %q does not match the format %s", f.Target, v, f.Date+f.DateTime)
+		}
+		if f.Date != "" {
+			v = t.Format("2006-01-02")
+		} else {
+			v = t.UTC().Format("2006-01-02T15:04:05.000Z")
+		}
+	}
+	return v, nil
+}
+
+// Transform reads the CSV from r, applies the mapping and writes the result to w.
+// Both the input and output use the given delimiter.
+func (m *Mapping) Transform(r io.Reader, w io.Writer, delimiter rune) error {
+	cr := csv.NewReader(r)
+	cr.Comma = delimiter
+	cr.ReuseRecord = true
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return errors.New("mapping: file is empty")
+	}
+	if err != nil {
+		return err
+	}
+	outHeader, columns, err := m.compile(header)
+	if err != nil {
+		return err
+	}
+	if err := cw.Write(outHeader); err != nil {
+		return err
+	}
+	out := make([]string, len(columns))
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for i := range columns {
+			if out[i], err = columns[i].value(record); err != nil {
+				return fmt.Errorf("mapping: row %d: %w", row, err)
+			}
+		}
+		if err := cw.Write(out); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// NewReader returns a reader of the mapped CSV.  The mapping is applied as the data is read,
+// so the whole file is never held in memory.  Closing the returned reader also closes r.
+func (m *Mapping) NewReader(r io.ReadCloser, delimiter rune) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(m.Transform(r, pw, delimiter))
+	}()
+	return &mappedReader{PipeReader: pr, source: r}
+}
+
+type mappedReader struct {
+	*io.PipeReader
+	source io.Closer
+}
+
+func (r *mappedReader) Close() error {
+	r.PipeReader.Close()
+	return r.source.Close()
+}
+
+// layoutTokens are replaced with their Go time layout equivalent, longest first
+var layoutTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"}, {"YY", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"DD", "02"}, {"D", "2"},
+	{"HH", "15"}, {"hh", "03"}, {"h", "3"},
+	{"mm", "04"}, {"ss", "05"}, {"SSS", "000"},
+	{"A", "PM"}, {"a", "pm"}, {"Z", "Z07:00"},
+}
+
+// Layout converts a date format such as DD/MM/YYYY HH:mm into a Go time layout.
+// Formats that are already Go layouts, i.e. that contain 2006, are returned unchanged.
+func Layout(format string) string {
+	if strings.Contains(format, "2006") {
+		return format
+	}
+	var b strings.Builder
+next:
+	for i := 0; i < len(format); {
+		for _, t := range layoutTokens {
+			if strings.HasPrefix(format[i:], t.token) {
+				b.WriteString(t.layout)
+				i += len(t.token)
+				continue next
+			}
+		}
+		b.WriteByte(format[i])
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package mapping
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name      string
+		mapping   string
+		input     string
+		delimiter rune
+		want      string
+	}{
+		{
+			name: "rename, drop and pass through",
+			mapping: `
+fields:
+  - {target: LastName, source: SURNAME}
+drop: [INTERNAL_REF]
+`,
+			input: "ID,SURNAME,INTERNAL_REF,Email\n1,Smith,x,s@example.com\n",
+			want:  "LastName,ID,Email\nSmith,1,s@example.com\n",
+		},
+		{
+			name: "no passthrough",
+			mapping: `
+fields:
+  - {target: LastName, source: SURNAME}
+passthrough: false
+`,
+			input: "ID,SURNAME\n1,Smith\n",
+			want:  "LastName\nSmith\n",
+		},
+		{
+			name: "concat, value and transforms",
+			mapping: `
+fields:
+  - {target: Name, concat: [FIRST, MIDDLE, LAST], separator: " ", transform: [trim, upper]}
+  - {target: LeadSource, value: ERP}
+  - {target: Email, source: EMAIL, transform: [Trim, lower]}
+passthrough: false
+`,
+			input: "FIRST,MIDDLE,LAST,EMAIL\nJane,, Doe , J.Doe@Example.com \n",
+			want:  "Name,LeadSource,Email\nJANE  DOE,ERP,j.doe@example.com\n",
+		},
+		{
+			name: "lookup and default",
+			mapping: `
+fields:
+  - target: Industry
+    source: SECTOR
+    lookup: {FIN: Finance, TECH: Technology}
+    default: Other
+  - target: Rating
+    source: RATING
+    lookup: {H: Hot}
+passthrough: false
+`,
+			input: "SECTOR,RATING\nFIN,H\nAGR,C\n",
+			want:  "Industry,Rating\nFinance,Hot\nOther,C\n",
+		},
+		{
+			name: "dates",
+			mapping: `
+fields:
+  - {target: Birthdate, source: DOB, date: DD/MM/YYYY}
+  - {target: Created, source: CREATED, datetime: D MMM YYYY HH:mm, timezone: Europe/London}
+  - {target: Closed, source: CLOSED, datetime: "2006-01-02T15:04:05Z07:00"}
+passthrough: false
+`,
+			input: "DOB,CREATED,CLOSED\n04/03/1980,1 Jul 2021 09:30,2021-07-01T09:30:00+02:00\n,,\n",
+			want:  "Birthdate,Created,Closed\n1980-03-04,2021-07-01T08:30:00.000Z,2021-07-01T07:30:00.000Z\n,,\n",
+		},
+		{
+			name: "delimiter",
+			mapping: `
+fields:
+  - {target: LastName, source: SURNAME}
+`,
+			input:     "SURNAME;Notes\nSmith;a,b\n",
+			delimiter: ';',
+			want:      "LastName;Notes\nSmith;a,b\n",
+		},
+	}
+	for _, tt := range tests {
+		m, err := Parse([]byte(tt.mapping))
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if tt.delimiter == 0 {
+			tt.delimiter = ','
+		}
+		var b bytes.Buffer
+		if err := m.Transform(strings.NewReader(tt.input), &b, tt.delimiter); err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		input   string
+		want    string
+	}{
+		{"empty file", "fields: [{target: LastName, source: SURNAME}]", "", "file is empty"},
+		{"missing source", "fields: [{target: LastName, source: SURNAME}]", "NAME\nSmith\n", "LastName refers to column SURNAME"},
+		{"missing concat", "fields: [{target: Name, concat: [FIRST, LAST]}]", "FIRST\nJane\n", "Name refers to column LAST"},
+		{"missing drop", "drop: [REF]", "NAME\nSmith\n", "drop refers to column REF"},
+		{"duplicate output", "fields: [{target: Name, source: SURNAME}]", "SURNAME,name\nSmith,x\n", "column name would appear more than once"},
+		{"bad date", "fields: [{target: Birthdate, source: DOB, date: DD/MM/YYYY}]", "DOB\n04/03/1980\n1980-03-04\n", `row 2: Birthdate: "1980-03-04" does not match the format DD/MM/YYYY`},
+	}
+	for _, tt := range tests {
+		m, err := Parse([]byte(tt.mapping))
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		var b bytes.Buffer
+		err = m.Transform(strings.NewReader(tt.input), &b, ',')
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		} else if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		want    string
+	}{
+		{"no target", "fields: [{source: SURNAME}]", "field 1 has no target"},
+		{"duplicate target", "fields: [{target: Name, source: A}, {target: name, source: B}]", "duplicate target name"},
+		{"no source", "fields: [{target: Name}]", "exactly one of source, concat or value"},
+		{"two sources", "fields: [{target: Name, source: A, value: x}]", "exactly one of source, concat or value"},
+		{"unknown transform", "fields: [{target: Name, source: A, transform: [title]}]", "unknown transform title"},
+		{"date and datetime", "fields: [{target: D, source: A, date: YYYY, datetime: YYYY}]", "both date and datetime"},
+		{"timezone", "fields: [{target: D, source: A, datetime: YYYY, timezone: Nowhere/Special}]", "mapping: D:"},
+		{"unknown field", "fields: [{target: Name, sauce: A}]", "sauce"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.mapping))
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		} else if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"DD/MM/YYYY", "02/01/2006"},
+		{"D MMM YY", "2 Jan 06"},
+		{"MMMM D, YYYY h:mm A", "January 2, 2006 3:04 PM"},
+		{"YYYY-MM-DDTHH:mm:ss.SSSZ", "2006-01-02T15:04:05.000Z07:00"},
+		{"2006-01-02", "2006-01-02"},
+	}
+	for _, tt := range tests {
+		if got := Layout(tt.format); got != tt.want {
+			t.Errorf("Layout(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
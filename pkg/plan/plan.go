@@ -0,0 +1,226 @@
+// Package plan describes a load of several files into related objects and works out the
+// order in which they must be loaded so that records exist before they are referenced.
+//
+// A plan is usually loaded from a YAML file such as:
+//
+//	policy: stop
+//	steps:
+//	  - name: accounts
+//	    file: accounts.csv
+//	    object: Account
+//	    operation: upsert
+//	    external: ERP_Id__c
+//	  - file: contacts.xlsx
+//	    object: Contact
+//	    operation: upsert
+//	    external: ERP_Id__c
+//	    map: contacts.yaml
+//
+// Relative paths are relative to the plan file.
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Policies for what happens when a step fails
+const (
+	PolicyStop     = "stop"     // finish the steps already running, then stop
+	PolicyContinue = "continue" // carry on with the steps that don't depend on the failed step
+)
+
+// Plan is a list of steps to load, along with what to do if one of them fails
+type Plan struct {
+	Policy string `yaml:"policy,omitempty"` // default is stop
+	Steps  []Step `yaml:"steps"`
+}
+
+// Step is a single file to load into an object
+type Step struct {
+	Name      string   `yaml:"name,omitempty"` // default is the object name
+	File      string   `yaml:"file"`
+	Object    string   `yaml:"object"`
+	Operation string   `yaml:"operation,omitempty"` // insert, upsert or update, default is insert
+	External  string   `yaml:"external,omitempty"`  // external id field, required for upsert
+	Format    string   `yaml:"format,omitempty"`
+	Sheet     string   `yaml:"sheet,omitempty"`
+	Delimiter string   `yaml:"delimiter,omitempty"`
+	Encoding  string   `yaml:"encoding,omitempty"`
+	CRLF      bool     `yaml:"crlf,omitempty"`
+	Map       string   `yaml:"map,omitempty"`
+	After     []string `yaml:"after,omitempty"` // names of steps that must be loaded first, in addition to those found from the data
+
+	// DependsOn holds the names of all the steps that must be loaded first, and is set by Order
+	DependsOn []string `yaml:"-"`
+}
+
+// Load reads and checks the plan from the named YAML file, resolving file paths relative to it
+func Load(name string) (*Plan, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(name)
+	for i := range p.Steps {
+		s := &p.Steps[i]
+		if !filepath.IsAbs(s.File) {
+			s.File = filepath.Join(dir, s.File)
+		}
+		if s.Map != "" && !filepath.IsAbs(s.Map) {
+			s.Map = filepath.Join(dir, s.Map)
+		}
+	}
+	return p, nil
+}
+
+// Parse reads and checks the plan from YAML, filling in the defaults
+func Parse(b []byte) (*Plan, error) {
+	var p Plan
+	if err := yaml.UnmarshalStrict(b, &p); err != nil {
+		return nil, fmt.Errorf("plan: %w", err)
+	}
+	if p.Policy == "" {
+		p.Policy = PolicyStop
+	}
+	for i := range p.Steps {
+		s := &p.Steps[i]
+		if s.Name == "" {
+			s.Name = s.Object
+		}
+		if s.Operation == "" {
+			s.Operation = "insert"
+		}
+		s.Operation = strings.ToLower(s.Operation)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks the plan is complete and consistent
+func (p *Plan) Validate() error {
+	if p.Policy != PolicyStop && p.Policy != PolicyContinue {
+		return fmt.Errorf("plan: unknown policy %s, use stop or continue", p.Policy)
+	}
+	if len(p.Steps) == 0 {
+		return errors.New("plan: no steps")
+	}
+	names := make(map[string]bool)
+	for i, s := range p.Steps {
+		switch {
+		case s.Object == "":
+			return fmt.Errorf("plan: step %d: object is required", i+1)
+		case s.File == "":
+			return fmt.Errorf("plan: step %s: file is required", s.Name)
+		case s.File == "-":
+			return fmt.Errorf("plan: step %s: stdin can't be used in a plan", s.Name)
+		case s.Operation != "insert" && s.Operation != "upsert" && s.Operation != "update":
+			return fmt.Errorf("plan: step %s: unsupported operation %s, use insert, upsert or update", s.Name, s.Operation)
+		case s.Operation == "upsert" && s.External == "":
+			return fmt.Errorf("plan: step %s: external is required for upsert", s.Name)
+		case names[s.Name]:
+			return fmt.Errorf("plan: step %s: duplicate name, give the steps for the same object a name", s.Name)
+		}
+		names[s.Name] = true
+	}
+	for _, s := range p.Steps {
+		for _, a := range s.After {
+			if !names[a] {
+				return fmt.Errorf("plan: step %s: after refers to unknown step %s", s.Name, a)
+			}
+		}
+	}
+	return nil
+}
+
+// Order groups the steps into levels that must be loaded one after the other.  The steps within
+// a level don't depend on each other so can be loaded at the same time.  references returns the
+// objects that the records in a step refer to, and a step depends on every other step that loads
+// one of those objects as well as those listed in After.  It is an error for the steps to depend
+// on each other in a cycle.  The steps returned have DependsOn set.
+func (p *Plan) Order(references func(s Step) ([]string, error)) ([][]Step, error) {
+	byName := make(map[string]int)
+	byObject := make(map[string][]int)
+	for i, s := range p.Steps {
+		byName[s.Name] = i
+		byObject[strings.ToLower(s.Object)] = append(byObject[strings.ToLower(s.Object)], i)
+	}
+
+	// deps[i] holds the steps that step i depends on
+	deps := make([]map[int]bool, len(p.Steps))
+	for i, s := range p.Steps {
+		deps[i] = make(map[int]bool)
+		refs, err := references(s)
+		if err != nil {
+			return nil, fmt.Errorf("plan: step %s: %w", s.Name, err)
+		}
+		for _, r := range refs {
+			for _, j := range byObject[strings.ToLower(r)] {
+				// references to the same object, e.g. a parent account, are ignored; use after to order steps for the same object
+				if !strings.EqualFold(p.Steps[j].Object, s.Object) {
+					deps[i][j] = true
+				}
+			}
+		}
+		for _, a := range s.After {
+			deps[i][byName[a]] = true
+		}
+	}
+
+	var levels [][]Step
+	done := make(map[int]bool)
+	for len(done) < len(p.Steps) {
+		var level []int
+		for i := range p.Steps {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for j := range deps[i] {
+				if !done[j] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, i)
+			}
+		}
+		if len(level) == 0 {
+			var cycle []string
+			for i, s := range p.Steps {
+				if !done[i] {
+					cycle = append(cycle, s.Name)
+				}
+			}
+			sort.Strings(cycle)
+			return nil, fmt.Errorf("plan: steps depend on each other: %s", strings.Join(cycle, ", "))
+		}
+		steps := make([]Step, 0, len(level))
+		for _, i := range level {
+			done[i] = true
+			s := p.Steps[i]
+			s.DependsOn = nil
+			for j := range p.Steps {
+				if deps[i][j] {
+					s.DependsOn = append(s.DependsOn, p.Steps[j].Name)
+				}
+			}
+			steps = append(steps, s)
+		}
+		levels = append(levels, steps)
+	}
+	return levels, nil
+}
@@ -0,0 +1,175 @@
+package plan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// levelNames returns the names of the steps in each level, e.g. "accounts | contacts, cases"
+func levelNames(levels [][]Step) string {
+	var s []string
+	for _, level := range levels {
+		var names []string
+		for _, step := range level {
+			names = append(names, step.Name)
+		}
+		s = append(s, strings.Join(names, ", "))
+	}
+	return strings.Join(s, " | ")
+}
+
+func TestOrder(t *testing.T) {
+	refs := map[string][]string{
+		"Account":     {"User", "Account"},
+		"Contact":     {"account", "User"},
+		"Case":        {"Account", "Contact"},
+		"Opportunity": {"Account"},
+		"Product2":    nil,
+	}
+	references := func(s Step) ([]string, error) { return refs[s.Object], nil }
+
+	tests := []struct {
+		name      string
+		plan      string
+		want      string
+		dependsOn map[string]string
+	}{
+		{
+			name: "levels",
+			plan: `
+steps:
+  - {file: cases.csv, object: Case}
+  - {file: contacts.csv, object: Contact}
+  - {file: opportunities.csv, object: Opportunity}
+  - {file: accounts.csv, object: Account}
+  - {file: products.csv, object: Product2}
+`,
+			want:      "Account, Product2 | Contact, Opportunity | Case",
+			dependsOn: map[string]string{"Case": "Contact, Account", "Contact": "Account", "Account": ""},
+		},
+		{
+			name: "same object references ignored",
+			plan: `
+steps:
+  - {name: parents, file: parents.csv, object: Account}
+  - {name: children, file: children.csv, object: account}
+`,
+			want: "parents, children",
+		},
+		{
+			name: "after",
+			plan: `
+steps:
+  - {name: children, file: children.csv, object: Account, after: [parents]}
+  - {name: parents, file: parents.csv, object: Account}
+  - {file: products.csv, object: Product2, after: [children]}
+`,
+			want:      "parents | children | Product2",
+			dependsOn: map[string]string{"children": "parents", "Product2": "children"},
+		},
+	}
+	for _, tt := range tests {
+		p, err := Parse([]byte(tt.plan))
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		levels, err := p.Order(references)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if got := levelNames(levels); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+		for _, level := range levels {
+			for _, s := range level {
+				if want, ok := tt.dependsOn[s.Name]; ok && strings.Join(s.DependsOn, ", ") != want {
+					t.Errorf("%s: %s depends on %v, want %s", tt.name, s.Name, s.DependsOn, want)
+				}
+			}
+		}
+	}
+}
+
+func TestOrderErrors(t *testing.T) {
+	refs := map[string][]string{"Account": {"Contact"}, "Contact": {"Account"}}
+	references := func(s Step) ([]string, error) {
+		if s.Object == "Broken__c" {
+			return nil, errors.New("no such object")
+		}
+		return refs[s.Object], nil
+	}
+
+	tests := []struct {
+		name string
+		plan string
+		want string
+	}{
+		{
+			name: "cycle",
+			plan: `
+steps:
+  - {file: products.csv, object: Product2}
+  - {file: contacts.csv, object: Contact}
+  - {file: accounts.csv, object: Account}
+`,
+			want: "plan: steps depend on each other: Account, Contact",
+		},
+		{
+			name: "cycle with after",
+			plan: `
+steps:
+  - {name: a, file: a.csv, object: Product2, after: [b]}
+  - {name: b, file: b.csv, object: Product2, after: [a]}
+`,
+			want: "plan: steps depend on each other: a, b",
+		},
+		{
+			name: "references error",
+			plan: `
+steps:
+  - {file: broken.csv, object: Broken__c}
+`,
+			want: "plan: step Broken__c: no such object",
+		},
+	}
+	for _, tt := range tests {
+		p, err := Parse([]byte(tt.plan))
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if _, err := p.Order(references); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got error %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		plan string
+		want string
+	}{
+		{"no steps", "policy: stop", "no steps"},
+		{"unknown policy", "policy: retry\nsteps: [{file: a.csv, object: Account}]", "unknown policy retry"},
+		{"unknown field", "steps: [{file: a.csv, object: Account, extrnal: ERP__c}]", "extrnal"},
+		{"no object", "steps: [{file: a.csv}]", "step 1: object is required"},
+		{"no file", "steps: [{object: Account}]", "step Account: file is required"},
+		{"stdin", "steps: [{file: '-', object: Account}]", "stdin can't be used"},
+		{"operation", "steps: [{file: a.csv, object: Account, operation: delete}]", "unsupported operation delete"},
+		{"upsert without external", "steps: [{file: a.csv, object: Account, operation: Upsert}]", "external is required"},
+		{"duplicate", "steps: [{file: a.csv, object: Account}, {file: b.csv, object: Account}]", "duplicate name"},
+		{"unknown after", "steps: [{file: a.csv, object: Account, after: [contacts]}]", "unknown step contacts"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.plan))
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		} else if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
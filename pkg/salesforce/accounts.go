@@ -1 +1,66 @@
 package salesforce
+
+import (
+	"context"
+	"time"
+)
+
+// Account is a subset of the standard fields of an Account
+type Account struct {
+	ID                string     `sf:"Id" json:"-"`
+	Name              string     `json:"Name,omitempty"`
+	AccountNumber     string     `json:"AccountNumber,omitempty"`
+	Type              string     `json:"Type,omitempty"`
+	Industry          string     `json:"Industry,omitempty"`
+	ParentID          string     `sf:"ParentId" json:"ParentId,omitempty"`
+	OwnerID           string     `sf:"OwnerId" json:"OwnerId,omitempty"`
+	Phone             string     `json:"Phone,omitempty"`
+	Website           string     `json:"Website,omitempty"`
+	AnnualRevenue     *float64   `json:"AnnualRevenue,omitempty"`
+	NumberOfEmployees *int       `json:"NumberOfEmployees,omitempty"`
+	BillingStreet     string     `json:"BillingStreet,omitempty"`
+	BillingCity       string     `json:"BillingCity,omitempty"`
+	BillingState      string     `json:"BillingState,omitempty"`
+	BillingPostalCode string     `json:"BillingPostalCode,omitempty"`
+	BillingCountry    string     `json:"BillingCountry,omitempty"`
+	Description       string     `json:"Description,omitempty"`
+	CreatedDate       *time.Time `sf:"CreatedDate" json:"-"`
+	LastModifiedDate  *time.Time `sf:"LastModifiedDate" json:"-"`
+}
+
+// Get returns the account with the given id
+func (s *AccountService) Get(ctx context.Context, id string) (*Account, error) {
+	r, err := s.client.SObjectService.Get(ctx, "Account", id)
+	if err != nil {
+		return nil, err
+	}
+	var a Account
+	if err := r.Decode(&a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Create creates an account, returning its id
+func (s *AccountService) Create(ctx context.Context, a *Account) (string, error) {
+	res, err := s.client.SObjectService.Create(ctx, "Account", a)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// Update updates the fields of the account with the given id that are set in a
+func (s *AccountService) Update(ctx context.Context, id string, a *Account) error {
+	return s.client.SObjectService.Update(ctx, "Account", id, a)
+}
+
+// Upsert creates or updates the account with the given value in an external ID field
+func (s *AccountService) Upsert(ctx context.Context, field, value string, a *Account) (*SaveResult, error) {
+	return s.client.SObjectService.Upsert(ctx, "Account", field, value, a)
+}
+
+// Delete deletes the account with the given id
+func (s *AccountService) Delete(ctx context.Context, id string) error {
+	return s.client.SObjectService.Delete(ctx, "Account", id)
+}
@@ -1,12 +1,16 @@
 package salesforce
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // BulkType represents the type of bulk operation required for bulk operations
@@ -44,11 +48,33 @@ type JobInfo struct {
 	JobType                string  `json:"jobType"`
 	LineEnding             string  `json:"lineEnding"`
 	ColumnDelimiter        string  `json:"columnDelimiter"`
+	ExternalIDFieldName    string  `json:"externalIdFieldName"`
+	ErrorMessage           string  `json:"errorMessage"`
 	NumberRecordsProcessed int     `json:"numberRecordsProcessed"`
 	NumberRecordsFailed    int     `json:"numberRecordsFailed"`
 	Retries                int     `json:"retries"`
 }
 
+// Delimiter returns the character used for the given bulk column delimiter, e.g. COMMA or TAB.
+// An empty name returns the default delimiter, which is a comma.
+func Delimiter(name string) (rune, error) {
+	switch strings.ToUpper(name) {
+	case "", "COMMA":
+		return ',', nil
+	case "TAB":
+		return '\t', nil
+	case "SEMICOLON":
+		return ';', nil
+	case "PIPE":
+		return '|', nil
+	case "CARET":
+		return '^', nil
+	case "BACKQUOTE":
+		return '`', nil
+	}
+	return 0, fmt.Errorf("unsupported column delimiter: %s", name)
+}
+
 // BulkRequest represents the object required to send when creating a Job Request
 type BulkRequest struct {
 	Object              string `json:"object,omitempty"`              // e.g. Account
@@ -146,10 +172,11 @@ func (s *BulkService) UploadCSV(ctx context.Context, id string, payload io.Reade
 		return err
 	}
 	req.Header.Set("Content-Type", "text/csv")
-	if err := s.client.makeRequest(ctx, req, nil); err != nil {
+	res, err := s.client.doStream(ctx, req)
+	if err != nil {
 		return err
 	}
-	return nil
+	return res.Body.Close()
 }
 
 // ProcessJob marks a job as UploadComplete and begins processing
@@ -200,3 +227,101 @@ func (s *BulkService) GetFailedResults(ctx context.Context, jobType BulkType, id
 	// and return a typed object instead?
 	return res, nil
 }
+
+// GetUnprocessedRecords returns the records that were not processed for the specified ingest job,
+// for example because the job was aborted or failed.  The records are streamed from salesforce
+// rather than read into memory, so the caller must close the returned reader.
+func (s *BulkService) GetUnprocessedRecords(ctx context.Context, id string) (io.ReadCloser, error) {
+	sfurl := fmt.Sprintf("%s/services/data/%s/jobs/%s/%s/unprocessedrecords", s.client.BaseURL, s.client.Version, BulkTypeIngest, id)
+	req, err := http.NewRequest("GET", sfurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/csv")
+	return s.client.makeStreamRequest(ctx, req)
+}
+
+// WaitForJob polls the job at the given interval until it has completed, failed or been aborted.
+func (s *BulkService) WaitForJob(ctx context.Context, jobType BulkType, id string, interval time.Duration) (*JobInfo, error) {
+	for {
+		job, err := s.GetJob(ctx, jobType, id)
+		if err != nil {
+			return nil, err
+		}
+		switch job.State {
+		case "JobComplete", "Failed", "Aborted":
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// GetQueryResults returns a page of up to maxRecords results for a completed query job, starting at the
+// given locator.  Use an empty locator for the first page and 0 for maxRecords to let salesforce decide.
+// The locator for the next page is returned, and is empty when there are no more pages.
+// The caller must close the returned reader.
+func (s *BulkService) GetQueryResults(ctx context.Context, id, locator string, maxRecords int) (io.ReadCloser, string, error) {
+	sfurl := fmt.Sprintf("%s/services/data/%s/jobs/query/%s/results", s.client.BaseURL, s.client.Version, id)
+	params := url.Values{}
+	if locator != "" {
+		params.Set("locator", locator)
+	}
+	if maxRecords > 0 {
+		params.Set("maxRecords", strconv.Itoa(maxRecords))
+	}
+	if len(params) > 0 {
+		sfurl += "?" + params.Encode()
+	}
+	req, err := http.NewRequest("GET", sfurl, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "text/csv")
+	res, err := s.client.doStream(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+	next := res.Header.Get("Sforce-Locator")
+	if next == "null" {
+		next = ""
+	}
+	return res.Body, next, nil
+}
+
+// QueryResultsReader returns every page of results for a completed query job as a single CSV,
+// fetching each page as the previous one is read.  The caller must close the returned reader.
+func (s *BulkService) QueryResultsReader(ctx context.Context, id string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		locator := ""
+		for first := true; first || locator != ""; first = false {
+			page, next, err := s.GetQueryResults(ctx, id, locator, 0)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			br := bufio.NewReader(page)
+			if !first {
+				// each page repeats the header, which we only want once
+				if _, err := br.ReadString('\n'); err != nil && err != io.EOF {
+					page.Close()
+					pw.CloseWithError(err)
+					return
+				}
+			}
+			_, err = io.Copy(pw, br)
+			page.Close()
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			locator = next
+		}
+		pw.Close()
+	}()
+	return pr
+}
@@ -0,0 +1,166 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DescribeCache stores describe results between runs so they are only downloaded again when the
+// metadata has changed.  Set it on the Client to use it for Describe and DescribeGlobal.
+type DescribeCache interface {
+	// Get returns the cached response for the key and when salesforce last modified it
+	Get(key string) (data []byte, modified time.Time, ok bool)
+	// Put stores a response and when salesforce last modified it
+	Put(key string, data []byte, modified time.Time) error
+}
+
+// FileCache is a DescribeCache that keeps each response in a file under a directory
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a cache stored under dir.  The directory is created on first write.
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{dir: dir}
+}
+
+// Dir returns the directory the cache is stored in
+func (fc *FileCache) Dir() string {
+	return fc.dir
+}
+
+func (fc *FileCache) path(key string) string {
+	return filepath.Join(fc.dir, filepath.FromSlash(key)+".json")
+}
+
+// Get returns the cached response for the key, using the modification time of its file as
+// the time the response was last modified
+func (fc *FileCache) Get(key string) ([]byte, time.Time, bool) {
+	name := fc.path(key)
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	data, err := os.ReadFile(name)
+	if err != nil || !json.Valid(data) {
+		return nil, time.Time{}, false
+	}
+	return data, info.ModTime(), true
+}
+
+// Put writes the response to a temporary file and renames it, so a partly written response is never
+// read, even if the same key is written by more than one goroutine or process at once
+func (fc *FileCache) Put(key string, data []byte, modified time.Time) error {
+	name := fc.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chtimes(tmp, modified, modified)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// Clear removes everything in the cache
+func (fc *FileCache) Clear() error {
+	return os.RemoveAll(fc.dir)
+}
+
+// cacheKey returns the key for a describe result, which includes the org and API version so a
+// cache can be shared between clients
+func (c *Client) cacheKey(name string) string {
+	host := c.BaseURL
+	if u, err := url.Parse(c.BaseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return path.Join(host, c.Version, name)
+}
+
+// describeKey returns the cache key name for an object's describe result
+func describeKey(object string) string {
+	return "sobjects/" + strings.ToLower(object)
+}
+
+// getDescribe fetches a describe resource into v.  With a cache, the request is made with
+// If-Modified-Since and the cached response is used if the metadata hasn't changed.  The time
+// sent is the one salesforce gave for the cached response, so the local clock doesn't matter.
+func (c *Client) getDescribe(ctx context.Context, resource, key string, v interface{}) error {
+	req, err := http.NewRequest("GET", c.BaseURL+c.DataPath(resource), nil)
+	if err != nil {
+		return err
+	}
+	var cached []byte
+	if c.DescribeCache != nil {
+		key = c.cacheKey(key)
+		var modified time.Time
+		var ok bool
+		if cached, modified, ok = c.DescribeCache.Get(key); ok {
+			req.Header.Set("If-Modified-Since", modified.UTC().Format(http.TimeFormat))
+		}
+	}
+	res, err := c.do(ctx, req)
+	if errors.Is(err, ErrRequestContentNotChanged) && cached != nil {
+		return json.Unmarshal(cached, v)
+	}
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if c.DescribeCache != nil {
+		// the cache is only an optimisation, so failing to write it isn't an error
+		c.DescribeCache.Put(key, body, lastModified(res.Header))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// lastModified returns the time a response was last modified according to salesforce, from the
+// Last-Modified header or, failing that, the Date header
+func lastModified(h http.Header) time.Time {
+	for _, name := range []string{"Last-Modified", "Date"} {
+		if t, err := http.ParseTime(h.Get(name)); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// CachedDescribe returns the describe result for an object from the cache, without contacting
+// salesforce, or an error if it hasn't been cached
+func (c *Client) CachedDescribe(object string) (*DescribeResponse, error) {
+	if c.DescribeCache != nil {
+		if data, _, ok := c.DescribeCache.Get(c.cacheKey(describeKey(object))); ok {
+			var dr DescribeResponse
+			if err := json.Unmarshal(data, &dr); err == nil {
+				return &dr, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no cached description of %s", object)
+}
@@ -0,0 +1,449 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Limits of the composite resources
+const (
+	// MaxCompositeRequests is the most subrequests in a composite or batch request
+	MaxCompositeRequests = 25
+	// MaxTreeRecords is the most records, including children, in a composite tree request
+	MaxTreeRecords = 200
+	// MaxTreeDepth is the most levels of records in a composite tree request
+	MaxTreeDepth = 5
+	// MaxCollectionRecords is the most records in a single sObject collections request
+	MaxCollectionRecords = 200
+)
+
+// DataPath returns the path of a REST resource relative to the instance, e.g. DataPath("/sobjects/Account")
+// returns /services/data/v53.0/sobjects/Account.  Subrequests of composite requests use these paths.
+func (c *Client) DataPath(resource string) string {
+	return fmt.Sprintf("/services/data/%s%s", c.Version, resource)
+}
+
+func (s *CompositeService) url(resource string) string {
+	return s.client.BaseURL + s.client.DataPath("/composite"+resource)
+}
+
+// CompositeRequest is a series of subrequests that are run in order.  Later subrequests can refer
+// to the results of earlier ones with @{referenceId.field}, e.g. @{newAccount.id}.
+type CompositeRequest struct {
+	// AllOrNone rolls back every subrequest if any of them fails
+	AllOrNone bool `json:"allOrNone"`
+	// CollateSubrequests allows salesforce to run subrequests that don't depend on each other together
+	CollateSubrequests bool                  `json:"collateSubrequests,omitempty"`
+	CompositeRequest   []CompositeSubrequest `json:"compositeRequest"`
+}
+
+// CompositeSubrequest is a single request within a composite request
+type CompositeSubrequest struct {
+	Method string `json:"method"`
+	// URL is the path of the resource, such as one returned by DataPath
+	URL         string            `json:"url"`
+	ReferenceID string            `json:"referenceId"`
+	Body        interface{}       `json:"body,omitempty"`
+	HTTPHeaders map[string]string `json:"httpHeaders,omitempty"`
+}
+
+// CompositeResponse holds the responses to the subrequests, in the order they were made
+type CompositeResponse struct {
+	CompositeResponse []CompositeSubresponse `json:"compositeResponse"`
+}
+
+// CompositeSubresponse is the response to a single subrequest
+type CompositeSubresponse struct {
+	Body           json.RawMessage   `json:"body"`
+	HTTPHeaders    map[string]string `json:"httpHeaders"`
+	HTTPStatusCode int               `json:"httpStatusCode"`
+	ReferenceID    string            `json:"referenceId"`
+}
+
+// Response returns the response to the subrequest with the given reference id, or nil
+func (cr *CompositeResponse) Response(referenceID string) *CompositeSubresponse {
+	for i := range cr.CompositeResponse {
+		if cr.CompositeResponse[i].ReferenceID == referenceID {
+			return &cr.CompositeResponse[i]
+		}
+	}
+	return nil
+}
+
+// Err returns the first error that caused a subrequest to fail, if any did
+func (cr *CompositeResponse) Err() error {
+	for _, r := range cr.CompositeResponse {
+		if err := r.Err(); err != nil {
+			return fmt.Errorf("%s: %w", r.ReferenceID, err)
+		}
+	}
+	return nil
+}
+
+// Err returns the error in the response if the subrequest failed
+func (sr *CompositeSubresponse) Err() error {
+	return responseError(sr.HTTPStatusCode, sr.Body)
+}
+
+// Decode decodes the body of the response into v
+func (sr *CompositeSubresponse) Decode(v interface{}) error {
+	if err := sr.Err(); err != nil {
+		return err
+	}
+	if len(sr.Body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(sr.Body, v)
+}
+
+// Composite runs up to 25 subrequests in a single call.  The response is returned even when
+// subrequests fail, so check its Err method for their errors.
+func (s *CompositeService) Composite(ctx context.Context, cr CompositeRequest) (*CompositeResponse, error) {
+	if len(cr.CompositeRequest) == 0 {
+		return nil, errors.New("subrequests required")
+	}
+	if len(cr.CompositeRequest) > MaxCompositeRequests {
+		return nil, fmt.Errorf("too many subrequests: %d, the limit is %d", len(cr.CompositeRequest), MaxCompositeRequests)
+	}
+	refs := make(map[string]bool)
+	for _, r := range cr.CompositeRequest {
+		if r.ReferenceID == "" {
+			return nil, fmt.Errorf("reference id required for %s %s", r.Method, r.URL)
+		}
+		if refs[r.ReferenceID] {
+			return nil, fmt.Errorf("duplicate reference id %s", r.ReferenceID)
+		}
+		refs[r.ReferenceID] = true
+	}
+	var res CompositeResponse
+	if err := s.client.post(ctx, s.url(""), cr, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// BatchRequest is a series of independent subrequests.  Unlike a composite request, the
+// subrequests can't refer to each other and each one is committed separately.
+type BatchRequest struct {
+	// HaltOnError stops running subrequests after the first one that fails
+	HaltOnError   bool              `json:"haltOnError"`
+	BatchRequests []BatchSubrequest `json:"batchRequests"`
+}
+
+// BatchSubrequest is a single request within a batch
+type BatchSubrequest struct {
+	Method string `json:"method"`
+	// URL is the path of the resource relative to /services/data, e.g. v53.0/sobjects/Account
+	URL       string      `json:"url"`
+	RichInput interface{} `json:"richInput,omitempty"`
+}
+
+// BatchResponse holds the results of the subrequests, in the order they were made
+type BatchResponse struct {
+	HasErrors bool          `json:"hasErrors"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchResult is the result of a single subrequest
+type BatchResult struct {
+	StatusCode int             `json:"statusCode"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// Err returns the error in the result if the subrequest failed
+func (br *BatchResult) Err() error {
+	return responseError(br.StatusCode, br.Result)
+}
+
+// Decode decodes the result of the subrequest into v
+func (br *BatchResult) Decode(v interface{}) error {
+	if err := br.Err(); err != nil {
+		return err
+	}
+	if len(br.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(br.Result, v)
+}
+
+// Batch runs up to 25 independent subrequests in a single call
+func (s *CompositeService) Batch(ctx context.Context, br BatchRequest) (*BatchResponse, error) {
+	if len(br.BatchRequests) == 0 {
+		return nil, errors.New("subrequests required")
+	}
+	if len(br.BatchRequests) > MaxCompositeRequests {
+		return nil, fmt.Errorf("too many subrequests: %d, the limit is %d", len(br.BatchRequests), MaxCompositeRequests)
+	}
+	var res BatchResponse
+	if err := s.client.post(ctx, s.url("/batch"), br, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// TreeRecord is a record to insert with a composite tree request, along with its child records
+type TreeRecord struct {
+	// Type is the object of the record.  It can be left empty for the top level records.
+	Type string
+	// ReferenceID identifies the record in the results.  One is generated if it is empty.
+	ReferenceID string
+	Fields      map[string]interface{}
+	// Children are the child records by relationship name, e.g. Contacts
+	Children map[string][]*TreeRecord
+}
+
+// MarshalJSON encodes the record in the form expected by the composite tree resource
+func (tr *TreeRecord) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(tr.Fields)+len(tr.Children)+1)
+	for k, v := range tr.Fields {
+		m[k] = v
+	}
+	m["attributes"] = map[string]string{"type": tr.Type, "referenceId": tr.ReferenceID}
+	for relationship, children := range tr.Children {
+		m[relationship] = map[string]interface{}{"records": children}
+	}
+	return json.Marshal(m)
+}
+
+// TreeResponse holds the id given to each record of a composite tree request, or the errors if it failed
+type TreeResponse struct {
+	HasErrors bool         `json:"hasErrors"`
+	Results   []TreeResult `json:"results"`
+}
+
+// TreeResult is the outcome for a single record of a composite tree request
+type TreeResult struct {
+	ReferenceID string      `json:"referenceId"`
+	ID          string      `json:"id"`
+	Errors      []SaveError `json:"errors"`
+}
+
+// TreeError is returned when a composite tree request fails.  None of the records are inserted.
+type TreeError struct {
+	Results []TreeResult
+}
+
+func (e *TreeError) Error() string {
+	var msgs []string
+	for _, r := range e.Results {
+		for _, se := range r.Errors {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", r.ReferenceID, se.Error()))
+		}
+	}
+	return fmt.Sprintf("%s: %s", ErrBadRequest, strings.Join(msgs, "; "))
+}
+
+// Unwrap allows errors.Is to match ErrBadRequest
+func (e *TreeError) Unwrap() error {
+	return ErrBadRequest
+}
+
+// IDs returns the ids of the inserted records by reference id
+func (tr *TreeResponse) IDs() map[string]string {
+	ids := make(map[string]string, len(tr.Results))
+	for _, r := range tr.Results {
+		ids[r.ReferenceID] = r.ID
+	}
+	return ids
+}
+
+// Tree inserts up to 200 records of an object, along with their child records, in a single
+// transaction.  If any record fails, none are inserted and a *TreeError lists the problems.
+func (s *CompositeService) Tree(ctx context.Context, object string, records []*TreeRecord) (*TreeResponse, error) {
+	if object == "" {
+		return nil, errors.New("object required")
+	}
+	if len(records) == 0 {
+		return nil, errors.New("records required")
+	}
+	for _, r := range records {
+		if r.Type == "" {
+			r.Type = object
+		}
+	}
+	count := 0
+	refs := make(map[string]bool)
+	if err := prepareTree(records, 1, &count, refs); err != nil {
+		return nil, err
+	}
+	if count > MaxTreeRecords {
+		return nil, fmt.Errorf("too many records: %d, the limit is %d", count, MaxTreeRecords)
+	}
+	payload := map[string]interface{}{"records": records}
+	var res TreeResponse
+	if err := s.client.post(ctx, s.url("/tree/"+url.PathEscape(object)), payload, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// prepareTree checks the records and their children, counting them and generating missing reference ids
+func prepareTree(records []*TreeRecord, depth int, count *int, refs map[string]bool) error {
+	if depth > MaxTreeDepth {
+		return fmt.Errorf("records are nested more than %d levels deep", MaxTreeDepth)
+	}
+	for _, r := range records {
+		if r == nil {
+			return errors.New("nil record")
+		}
+		if r.Type == "" {
+			return fmt.Errorf("type required for child record %s", r.ReferenceID)
+		}
+		*count++
+		if r.ReferenceID == "" {
+			r.ReferenceID = fmt.Sprintf("ref%d", *count)
+			for refs[r.ReferenceID] {
+				r.ReferenceID += "_"
+			}
+		}
+		if refs[r.ReferenceID] {
+			return fmt.Errorf("duplicate reference id %s", r.ReferenceID)
+		}
+		refs[r.ReferenceID] = true
+		for _, children := range r.Children {
+			if err := prepareTree(children, depth+1, count, refs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CreateRecords creates records of an object with the sObject collections resource, 200 at a time.
+// The results are in the same order as the records.  With allOrNone, every record is rolled back if
+// any fails, which is only possible for up to 200 records.
+func (s *CompositeService) CreateRecords(ctx context.Context, object string, records []map[string]interface{}, allOrNone bool) ([]SaveResult, error) {
+	return s.saveRecords(ctx, "POST", "/sobjects", object, records, allOrNone)
+}
+
+// UpdateRecords updates records of an object with the sObject collections resource, 200 at a time.
+// Each record must include its Id.
+func (s *CompositeService) UpdateRecords(ctx context.Context, object string, records []map[string]interface{}, allOrNone bool) ([]SaveResult, error) {
+	for i, r := range records {
+		if id, _ := r["Id"].(string); id == "" {
+			return nil, fmt.Errorf("record %d has no Id", i+1)
+		}
+	}
+	return s.saveRecords(ctx, "PATCH", "/sobjects", object, records, allOrNone)
+}
+
+// UpsertRecords creates or updates records of an object by an external ID field with the sObject
+// collections resource, 200 at a time.  Created is set in the results of the records that were created.
+func (s *CompositeService) UpsertRecords(ctx context.Context, object, externalField string, records []map[string]interface{}, allOrNone bool) ([]SaveResult, error) {
+	if externalField == "" {
+		return nil, errors.New("external id field required")
+	}
+	resource := fmt.Sprintf("/sobjects/%s/%s", url.PathEscape(object), url.PathEscape(externalField))
+	return s.saveRecords(ctx, "PATCH", resource, object, records, allOrNone)
+}
+
+// DeleteRecords deletes records by id with the sObject collections resource, 200 at a time
+func (s *CompositeService) DeleteRecords(ctx context.Context, ids []string, allOrNone bool) ([]SaveResult, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("ids required")
+	}
+	if allOrNone && len(ids) > MaxCollectionRecords {
+		return nil, fmt.Errorf("allOrNone is limited to %d records", MaxCollectionRecords)
+	}
+	var results []SaveResult
+	for start := 0; start < len(ids); start += MaxCollectionRecords {
+		end := start + MaxCollectionRecords
+		if end > len(ids) {
+			end = len(ids)
+		}
+		sfurl := fmt.Sprintf("%s?ids=%s&allOrNone=%t", s.url("/sobjects"), url.QueryEscape(strings.Join(ids[start:end], ",")), allOrNone)
+		req, err := http.NewRequest("DELETE", sfurl, nil)
+		if err != nil {
+			return results, err
+		}
+		var res []SaveResult
+		if err := s.client.makeRequest(ctx, req, &res); err != nil {
+			return results, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
+
+// saveRecords sends the records to a collections resource in chunks, returning the results so far
+// if a request fails
+func (s *CompositeService) saveRecords(ctx context.Context, method, resource, object string, records []map[string]interface{}, allOrNone bool) ([]SaveResult, error) {
+	if object == "" {
+		return nil, errors.New("object required")
+	}
+	if len(records) == 0 {
+		return nil, errors.New("records required")
+	}
+	if allOrNone && len(records) > MaxCollectionRecords {
+		return nil, fmt.Errorf("allOrNone is limited to %d records", MaxCollectionRecords)
+	}
+	var results []SaveResult
+	for start := 0; start < len(records); start += MaxCollectionRecords {
+		end := start + MaxCollectionRecords
+		if end > len(records) {
+			end = len(records)
+		}
+		chunk := make([]map[string]interface{}, end-start)
+		for i, r := range records[start:end] {
+			// copy the record so the caller's map isn't changed
+			rec := make(map[string]interface{}, len(r)+1)
+			for k, v := range r {
+				rec[k] = v
+			}
+			rec["attributes"] = map[string]string{"type": object}
+			chunk[i] = rec
+		}
+		payload := map[string]interface{}{"allOrNone": allOrNone, "records": chunk}
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return results, err
+		}
+		req, err := http.NewRequest(method, s.url(resource), strings.NewReader(string(payloadJSON)))
+		if err != nil {
+			return results, err
+		}
+		var res []SaveResult
+		if err := s.client.makeRequest(ctx, req, &res); err != nil {
+			return results, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
+
+// post sends v as JSON and decodes the response into res
+func (c *Client) post(ctx context.Context, sfurl string, v, res interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", sfurl, strings.NewReader(string(payload)))
+	if err != nil {
+		return err
+	}
+	return c.makeRequest(ctx, req, res)
+}
+
+// responseError converts the status and body of a subrequest's response to an error, if it failed
+func responseError(status int, body json.RawMessage) error {
+	if status < http.StatusMultipleChoices {
+		return nil
+	}
+	var errs []BadRequestError
+	if err := json.Unmarshal(body, &errs); err == nil && len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			msgs[i] = fmt.Sprintf("%s: %s", e.ErrorCode, e.Message)
+			if len(e.Fields) > 0 {
+				msgs[i] += " (" + strings.Join(e.Fields, ", ") + ")"
+			}
+		}
+		return fmt.Errorf("salesforce: %d %s", status, strings.Join(msgs, "; "))
+	}
+	return fmt.Errorf("salesforce: %d %s", status, http.StatusText(status))
+}
@@ -0,0 +1,68 @@
+package salesforce
+
+import (
+	"context"
+	"time"
+)
+
+// Contact is a subset of the standard fields of a Contact
+type Contact struct {
+	ID                string     `sf:"Id" json:"-"`
+	AccountID         string     `sf:"AccountId" json:"AccountId,omitempty"`
+	Salutation        string     `json:"Salutation,omitempty"`
+	FirstName         string     `json:"FirstName,omitempty"`
+	LastName          string     `json:"LastName,omitempty"`
+	Name              string     `sf:"Name" json:"-"`
+	Title             string     `json:"Title,omitempty"`
+	Department        string     `json:"Department,omitempty"`
+	Email             string     `json:"Email,omitempty"`
+	Phone             string     `json:"Phone,omitempty"`
+	MobilePhone       string     `json:"MobilePhone,omitempty"`
+	MailingStreet     string     `json:"MailingStreet,omitempty"`
+	MailingCity       string     `json:"MailingCity,omitempty"`
+	MailingState      string     `json:"MailingState,omitempty"`
+	MailingPostalCode string     `json:"MailingPostalCode,omitempty"`
+	MailingCountry    string     `json:"MailingCountry,omitempty"`
+	Birthdate         string     `json:"Birthdate,omitempty"` // YYYY-MM-DD
+	OwnerID           string     `sf:"OwnerId" json:"OwnerId,omitempty"`
+	Description       string     `json:"Description,omitempty"`
+	CreatedDate       *time.Time `sf:"CreatedDate" json:"-"`
+	LastModifiedDate  *time.Time `sf:"LastModifiedDate" json:"-"`
+}
+
+// Get returns the contact with the given id
+func (s *ContactService) Get(ctx context.Context, id string) (*Contact, error) {
+	r, err := s.client.SObjectService.Get(ctx, "Contact", id)
+	if err != nil {
+		return nil, err
+	}
+	var c Contact
+	if err := r.Decode(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Create creates a contact, returning its id
+func (s *ContactService) Create(ctx context.Context, c *Contact) (string, error) {
+	res, err := s.client.SObjectService.Create(ctx, "Contact", c)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// Update updates the fields of the contact with the given id that are set in c
+func (s *ContactService) Update(ctx context.Context, id string, c *Contact) error {
+	return s.client.SObjectService.Update(ctx, "Contact", id, c)
+}
+
+// Upsert creates or updates the contact with the given value in an external ID field
+func (s *ContactService) Upsert(ctx context.Context, field, value string, c *Contact) (*SaveResult, error) {
+	return s.client.SObjectService.Upsert(ctx, "Contact", field, value, c)
+}
+
+// Delete deletes the contact with the given id
+func (s *ContactService) Delete(ctx context.Context, id string) error {
+	return s.client.SObjectService.Delete(ctx, "Contact", id)
+}
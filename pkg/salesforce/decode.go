@@ -0,0 +1,304 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts of the date and time values returned by salesforce.  Date times use the same
+// layouts as validation.
+var (
+	dateLayout  = "2006-01-02"
+	timeLayouts = []string{"15:04:05.000Z", "15:04:05Z", "15:04:05.000", "15:04:05"}
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// QueryInto runs a SOQL query and decodes every record into v, which must be a pointer to a slice
+// of structs or pointers to structs, e.g.
+//
+//	type Contact struct {
+//		ID        string     `sf:"Id"`
+//		LastName  string     `sf:"LastName"`
+//		Birthdate *time.Time `sf:"Birthdate"`
+//		Account   *struct {
+//			Name string `sf:"Name"`
+//		} `sf:"Account"`
+//		Cases []struct {
+//			Subject string `sf:"Subject"`
+//		} `sf:"Cases"`
+//	}
+//	var contacts []Contact
+//	err := client.QueryInto(ctx, "SELECT Id, LastName, Birthdate, Account.Name, (SELECT Subject FROM Cases) FROM Contact", &contacts)
+//
+// See Record.Decode for how fields are matched and converted.
+func (c *Client) QueryInto(ctx context.Context, soql string, v interface{}) error {
+	it, err := c.Query(ctx, soql)
+	if err != nil {
+		return err
+	}
+	return it.DecodeAll(v)
+}
+
+// QueryAllInto is like QueryInto, but includes records that have been deleted or archived
+func (c *Client) QueryAllInto(ctx context.Context, soql string, v interface{}) error {
+	it, err := c.QueryAll(ctx, soql)
+	if err != nil {
+		return err
+	}
+	return it.DecodeAll(v)
+}
+
+// Decode decodes the current record into v, which must be a pointer to a struct
+func (it *QueryIterator) Decode(v interface{}) error {
+	if it.record == nil {
+		return errors.New("salesforce: no current record")
+	}
+	return it.record.Decode(v)
+}
+
+// DecodeAll decodes the remaining records into v, which must be a pointer to a slice of structs
+// or pointers to structs, appending them to any that are already there
+func (it *QueryIterator) DecodeAll(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("salesforce: decode requires a pointer to a slice, not %T", v)
+	}
+	slice := rv.Elem()
+	for it.Next() {
+		elem := reflect.New(slice.Type().Elem()).Elem()
+		if err := decodeInto(elem, it.record); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return it.Err()
+}
+
+// Decode decodes the record into v, which must be a pointer to a struct.  Fields are matched, ignoring
+// case, by the name in an sf tag, then the name in a json tag, then the name of the struct field.  Fields
+// tagged sf:"-" are skipped.  Parent relationships decode into structs, and child relationships into
+// slices of structs.  Dates, date times and times decode into time.Time, and multi-select picklists can
+// decode into []string.  Null values leave pointers nil and other types at their zero value.
+func (r *Record) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("salesforce: decode requires a non-nil pointer, not %T", v)
+	}
+	return decodeInto(rv.Elem(), r)
+}
+
+// decodeInto decodes a field value into v
+func decodeInto(v reflect.Value, value interface{}) error {
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeInto(v.Elem(), value)
+	}
+	if v.Type() != timeType && reflect.PtrTo(v.Type()).Implements(unmarshalerType) {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return v.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(b)
+	}
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	switch value := value.(type) {
+	case *Record:
+		if v.Kind() == reflect.Struct {
+			return decodeStruct(v, value)
+		}
+	case *QueryResponse:
+		if v.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(v.Type(), 0, len(value.Records))
+			for _, child := range value.Records {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if err := decodeInto(elem, child); err != nil {
+					return err
+				}
+				slice = reflect.Append(slice, elem)
+			}
+			v.Set(slice)
+			return nil
+		}
+	case string:
+		return decodeString(v, value)
+	case json.Number:
+		return decodeNumber(v, value)
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(value)
+			return nil
+		}
+	}
+
+	// anything else, such as compound address fields into a map or struct, goes through JSON
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v.Addr().Interface()); err != nil {
+		return fmt.Errorf("salesforce: cannot decode %s into %s", b, v.Type())
+	}
+	return nil
+}
+
+// decodeStruct decodes the fields of a record into the matching fields of a struct
+func decodeStruct(v reflect.Value, r *Record) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue // unexported
+		}
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && sf.Tag.Get("sf") == "" && sf.Tag.Get("json") == "" {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := decodeStruct(fv, r); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		value, ok := r.Get(name)
+		if !ok {
+			continue
+		}
+		if err := decodeInto(v.Field(i), value); err != nil {
+			return fmt.Errorf("salesforce: field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// fieldName returns the salesforce field name for a struct field
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"sf", "json"} {
+		if tag, ok := sf.Tag.Lookup(key); ok {
+			if name := strings.Split(tag, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+	return sf.Name
+}
+
+func decodeString(v reflect.Value, s string) error {
+	switch {
+	case v.Type() == timeType:
+		t, err := parseTime(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+		return nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		// multi-select picklist values are separated by semicolons
+		var values []string
+		if s != "" {
+			values = strings.Split(s, ";")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			slice.Index(i).SetString(value)
+		}
+		v.Set(slice)
+		return nil
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	}
+	return decodeNumber(v, json.Number(s))
+}
+
+func decodeNumber(v reflect.Value, n json.Number) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// salesforce returns whole numbers in number fields as 1.0
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(int64(f)) || f != float64(int64(f)) {
+			return fmt.Errorf("%s does not fit in %s", n, v.Type())
+		}
+		v.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return err
+		}
+		if f < 0 || v.OverflowUint(uint64(f)) || f != float64(uint64(f)) {
+			return fmt.Errorf("%s does not fit in %s", n, v.Type())
+		}
+		v.SetUint(uint64(f))
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(string(n))
+	default:
+		return fmt.Errorf("cannot decode %s into %s", n, v.Type())
+	}
+	return nil
+}
+
+// parseTime parses a salesforce date, date time or time value
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a date or time", s)
+}
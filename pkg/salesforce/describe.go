@@ -3,109 +3,57 @@ package salesforce
 import (
 	"context"
 	"errors"
-	"fmt"
-	"net/http"
+	"net/url"
+	"strings"
 )
 
-// DescribeResponse represents the fields for describing an object.
-// Note that not all fields are provided here.  Feel free to add extra fields if you need them.
+// DescribeResponse represents the metadata of an object, returned by Describe
 type DescribeResponse struct {
-	Fields []struct {
-		Aggregatable             bool        `json:"aggregatable"`
-		AiPredictionField        bool        `json:"aiPredictionField"`
-		AutoNumber               bool        `json:"autoNumber"`
-		ByteLength               int         `json:"byteLength"`
-		Calculated               bool        `json:"calculated"`
-		CalculatedFormula        string      `json:"calculatedFormula"`
-		CascadeDelete            bool        `json:"cascadeDelete"`
-		CaseSensitive            bool        `json:"caseSensitive"`
-		CompoundFieldName        string      `json:"compoundFieldName"`
-		ControllerName           string      `json:"controllerName"`
-		Createable               bool        `json:"createable"`
-		Custom                   bool        `json:"custom"`
-		DefaultValue             interface{} `json:"defaultValue"`
-		DefaultValueFormula      string      `json:"defaultValueFormula"`
-		DefaultedOnCreate        bool        `json:"defaultedOnCreate"`
-		DependentPicklist        bool        `json:"dependentPicklist"`
-		DeprecatedAndHidden      bool        `json:"deprecatedAndHidden"`
-		Digits                   int         `json:"digits"`
-		DisplayLocationInDecimal bool        `json:"displayLocationInDecimal"`
-		Encrypted                bool        `json:"encrypted"`
-		ExternalID               bool        `json:"externalId"`
-		ExtraTypeInfo            string      `json:"extraTypeInfo"`
-		Filterable               bool        `json:"filterable"`
-		// FilteredLookupInfo           interface{} `json:"filteredLookupInfo"`
-		FormulaTreatNullNumberAsZero bool   `json:"formulaTreatNullNumberAsZero"`
-		Groupable                    bool   `json:"groupable"`
-		HighScaleNumber              bool   `json:"highScaleNumber"`
-		HTMLFormatted                bool   `json:"htmlFormatted"`
-		IDLookup                     bool   `json:"idLookup"`
-		InlineHelpText               string `json:"inlineHelpText"`
-		Label                        string `json:"label"`
-		Length                       int    `json:"length"`
-		// Mask                         interface{} `json:"mask"`
-		// MaskType                     interface{} `json:"maskType"`
-		Name           string `json:"name"`
-		NameField      bool   `json:"nameField"`
-		NamePointing   bool   `json:"namePointing"`
-		Nillable       bool   `json:"nillable"`
-		Permissionable bool   `json:"permissionable"`
-		PicklistValues []struct {
-			Active       bool   `json:"active"`
-			DefaultValue bool   `json:"defaultValue"`
-			Label        string `json:"label"`
-			ValidFor     string `json:"validFor"`
-			Value        string `json:"value"`
-		} `json:"picklistValues"`
-		PolymorphicForeignKey bool `json:"polymorphicForeignKey"`
-		Precision             int  `json:"precision"`
-		QueryByDistance       bool `json:"queryByDistance"`
-		// ReferenceTargetField    interface{}   `json:"referenceTargetField"`
-		ReferenceTo      []string `json:"referenceTo"`
-		RelationshipName string   `json:"relationshipName"`
-		// RelationshipOrder       interface{} `json:"relationshipOrder"`
-		RestrictedDelete        bool   `json:"restrictedDelete"`
-		RestrictedPicklist      bool   `json:"restrictedPicklist"`
-		Scale                   int    `json:"scale"`
-		SearchPrefilterable     bool   `json:"searchPrefilterable"`
-		SoapType                string `json:"soapType"`
-		Sortable                bool   `json:"sortable"`
-		Type                    string `json:"type"`
-		Unique                  bool   `json:"unique"`
-		Updateable              bool   `json:"updateable"`
-		WriteRequiresMasterRead bool   `json:"writeRequiresMasterRead"`
-	} `json:"fields"`
-	IsSubtype       bool   `json:"isSubtype"`
-	KeyPrefix       string `json:"keyPrefix"`
-	Label           string `json:"label"`
-	LabelPlural     string `json:"labelPlural"`
-	Name            string `json:"name"`
-	Queryable       bool   `json:"queryable"`
-	RecordTypeInfos []struct {
-		Active                   bool   `json:"active"`
-		Available                bool   `json:"available"`
-		DefaultRecordTypeMapping bool   `json:"defaultRecordTypeMapping"`
-		DeveloperName            string `json:"developerName"`
-		Master                   bool   `json:"master"`
-		Name                     string `json:"name"`
-		RecordTypeID             string `json:"recordTypeId"`
-		Urls                     struct {
-			Layout string `json:"layout"`
-		} `json:"urls"`
-	} `json:"recordTypeInfos"`
-	Replicateable         bool   `json:"replicateable"`
-	Retrieveable          bool   `json:"retrieveable"`
-	SearchLayoutable      bool   `json:"searchLayoutable"`
-	Searchable            bool   `json:"searchable"`
-	SobjectDescribeOption string `json:"sobjectDescribeOption"`
-	SupportedScopes       []struct {
-		Label string `json:"label"`
-		Name  string `json:"name"`
-	} `json:"supportedScopes"`
-	Triggerable bool `json:"triggerable"`
-	Undeletable bool `json:"undeletable"`
-	Updateable  bool `json:"updateable"`
-	Urls        struct {
+	ActionOverrides       []ActionOverride    `json:"actionOverrides"`
+	Activateable          bool                `json:"activateable"`
+	AssociateEntityType   string              `json:"associateEntityType"`
+	AssociateParentEntity string              `json:"associateParentEntity"`
+	ChildRelationships    []ChildRelationship `json:"childRelationships"`
+	CompactLayoutable     bool                `json:"compactLayoutable"`
+	Createable            bool                `json:"createable"`
+	Custom                bool                `json:"custom"`
+	CustomSetting         bool                `json:"customSetting"`
+	DeepCloneable         bool                `json:"deepCloneable"`
+	DefaultImplementation string              `json:"defaultImplementation"`
+	Deletable             bool                `json:"deletable"`
+	DeprecatedAndHidden   bool                `json:"deprecatedAndHidden"`
+	ExtendedBy            string              `json:"extendedBy"`
+	ExtendsInterfaces     string              `json:"extendsInterfaces"`
+	FeedEnabled           bool                `json:"feedEnabled"`
+	Fields                []Field             `json:"fields"`
+	HasSubtypes           bool                `json:"hasSubtypes"`
+	ImplementedBy         string              `json:"implementedBy"`
+	ImplementsInterfaces  string              `json:"implementsInterfaces"`
+	IsInterface           bool                `json:"isInterface"`
+	IsSubtype             bool                `json:"isSubtype"`
+	KeyPrefix             string              `json:"keyPrefix"`
+	Label                 string              `json:"label"`
+	LabelPlural           string              `json:"labelPlural"`
+	Layoutable            bool                `json:"layoutable"`
+	Listviewable          bool                `json:"listviewable"`
+	LookupLayoutable      bool                `json:"lookupLayoutable"`
+	Mergeable             bool                `json:"mergeable"`
+	MruEnabled            bool                `json:"mruEnabled"`
+	Name                  string              `json:"name"`
+	NamedLayoutInfos      []NamedLayoutInfo   `json:"namedLayoutInfos"`
+	NetworkScopeFieldName string              `json:"networkScopeFieldName"`
+	Queryable             bool                `json:"queryable"`
+	RecordTypeInfos       []RecordTypeInfo    `json:"recordTypeInfos"`
+	Replicateable         bool                `json:"replicateable"`
+	Retrieveable          bool                `json:"retrieveable"`
+	SearchLayoutable      bool                `json:"searchLayoutable"`
+	Searchable            bool                `json:"searchable"`
+	SobjectDescribeOption string              `json:"sobjectDescribeOption"`
+	SupportedScopes       []SupportedScope    `json:"supportedScopes"`
+	Triggerable           bool                `json:"triggerable"`
+	Undeletable           bool                `json:"undeletable"`
+	Updateable            bool                `json:"updateable"`
+	Urls                  struct {
 		CompactLayouts   string `json:"compactLayouts"`
 		RowTemplate      string `json:"rowTemplate"`
 		ApprovalLayouts  string `json:"approvalLayouts"`
@@ -120,70 +68,370 @@ type DescribeResponse struct {
 	} `json:"urls"`
 }
 
-func (s *AccountService) Describe(ctx context.Context) (*DescribeResponse, error) {
-	sfurl := fmt.Sprintf("%s/services/data/%s/sobjects/Account/describe", s.client.BaseURL, s.client.Version)
-	req, err := http.NewRequest("GET", sfurl, nil)
-	if err != nil {
-		return nil, err
+// Field describes a field of an object
+type Field struct {
+	Aggregatable                 bool                `json:"aggregatable"`
+	AiPredictionField            bool                `json:"aiPredictionField"`
+	AutoNumber                   bool                `json:"autoNumber"`
+	ByteLength                   int                 `json:"byteLength"`
+	Calculated                   bool                `json:"calculated"`
+	CalculatedFormula            string              `json:"calculatedFormula"`
+	CascadeDelete                bool                `json:"cascadeDelete"`
+	CaseSensitive                bool                `json:"caseSensitive"`
+	CompoundFieldName            string              `json:"compoundFieldName"`
+	ControllerName               string              `json:"controllerName"`
+	Createable                   bool                `json:"createable"`
+	Custom                       bool                `json:"custom"`
+	DefaultValue                 interface{}         `json:"defaultValue"`
+	DefaultValueFormula          string              `json:"defaultValueFormula"`
+	DefaultedOnCreate            bool                `json:"defaultedOnCreate"`
+	DependentPicklist            bool                `json:"dependentPicklist"`
+	DeprecatedAndHidden          bool                `json:"deprecatedAndHidden"`
+	Digits                       int                 `json:"digits"`
+	DisplayLocationInDecimal     bool                `json:"displayLocationInDecimal"`
+	Encrypted                    bool                `json:"encrypted"`
+	ExternalID                   bool                `json:"externalId"`
+	ExtraTypeInfo                string              `json:"extraTypeInfo"`
+	Filterable                   bool                `json:"filterable"`
+	FilteredLookupInfo           *FilteredLookupInfo `json:"filteredLookupInfo"`
+	FormulaTreatNullNumberAsZero bool                `json:"formulaTreatNullNumberAsZero"`
+	Groupable                    bool                `json:"groupable"`
+	HighScaleNumber              bool                `json:"highScaleNumber"`
+	HTMLFormatted                bool                `json:"htmlFormatted"`
+	IDLookup                     bool                `json:"idLookup"`
+	InlineHelpText               string              `json:"inlineHelpText"`
+	Label                        string              `json:"label"`
+	Length                       int                 `json:"length"`
+	Mask                         string              `json:"mask"`
+	MaskType                     string              `json:"maskType"`
+	Name                         string              `json:"name"`
+	NameField                    bool                `json:"nameField"`
+	NamePointing                 bool                `json:"namePointing"`
+	Nillable                     bool                `json:"nillable"`
+	Permissionable               bool                `json:"permissionable"`
+	PicklistValues               []PicklistValue     `json:"picklistValues"`
+	PolymorphicForeignKey        bool                `json:"polymorphicForeignKey"`
+	Precision                    int                 `json:"precision"`
+	QueryByDistance              bool                `json:"queryByDistance"`
+	ReferenceTargetField         string              `json:"referenceTargetField"`
+	ReferenceTo                  []string            `json:"referenceTo"`
+	RelationshipName             string              `json:"relationshipName"`
+	// RelationshipOrder is 0 or 1 for the master-detail fields of a junction object, and nil otherwise
+	RelationshipOrder       *int   `json:"relationshipOrder"`
+	RestrictedDelete        bool   `json:"restrictedDelete"`
+	RestrictedPicklist      bool   `json:"restrictedPicklist"`
+	Scale                   int    `json:"scale"`
+	SearchPrefilterable     bool   `json:"searchPrefilterable"`
+	SoapType                string `json:"soapType"`
+	Sortable                bool   `json:"sortable"`
+	Type                    string `json:"type"`
+	Unique                  bool   `json:"unique"`
+	Updateable              bool   `json:"updateable"`
+	WriteRequiresMasterRead bool   `json:"writeRequiresMasterRead"`
+}
+
+// RequiredOnCreate reports whether a value must be given for the field when a record is created
+func (f *Field) RequiredOnCreate() bool {
+	return f.Createable && !f.Nillable && !f.DefaultedOnCreate && f.Type != "boolean"
+}
+
+// FilteredLookupInfo describes the lookup filter of a reference field
+type FilteredLookupInfo struct {
+	ControllingFields []string `json:"controllingFields"`
+	Dependent         bool     `json:"dependent"`
+	OptionalFilter    bool     `json:"optionalFilter"`
+}
+
+// PicklistValue is a value of a picklist field
+type PicklistValue struct {
+	Active       bool   `json:"active"`
+	DefaultValue bool   `json:"defaultValue"`
+	Label        string `json:"label"`
+	// ValidFor is a base64 bitmap of the values of the controlling field this value is valid for
+	ValidFor string `json:"validFor"`
+	Value    string `json:"value"`
+}
+
+// RecordTypeInfo describes a record type of an object
+type RecordTypeInfo struct {
+	Active                   bool   `json:"active"`
+	Available                bool   `json:"available"`
+	DefaultRecordTypeMapping bool   `json:"defaultRecordTypeMapping"`
+	DeveloperName            string `json:"developerName"`
+	Master                   bool   `json:"master"`
+	Name                     string `json:"name"`
+	RecordTypeID             string `json:"recordTypeId"`
+	Urls                     struct {
+		Layout string `json:"layout"`
+	} `json:"urls"`
+}
+
+// ChildRelationship describes a relationship from another object to this one, which can be
+// queried with a subquery using its relationship name
+type ChildRelationship struct {
+	CascadeDelete       bool     `json:"cascadeDelete"`
+	ChildSObject        string   `json:"childSObject"`
+	DeprecatedAndHidden bool     `json:"deprecatedAndHidden"`
+	Field               string   `json:"field"`
+	JunctionIDListNames []string `json:"junctionIdListNames"`
+	JunctionReferenceTo []string `json:"junctionReferenceTo"`
+	RelationshipName    string   `json:"relationshipName"`
+	RestrictedDelete    bool     `json:"restrictedDelete"`
+}
+
+// ActionOverride is a button or link that has been overridden for the object
+type ActionOverride struct {
+	FormFactor         string `json:"formFactor"`
+	IsAvailableInTouch bool   `json:"isAvailableInTouch"`
+	Name               string `json:"name"`
+	PageID             string `json:"pageId"`
+	URL                string `json:"url"`
+}
+
+// NamedLayoutInfo is a layout of the object other than the default
+type NamedLayoutInfo struct {
+	Name string `json:"name"`
+}
+
+// SupportedScope is a scope that can be used in a USING SCOPE clause for the object
+type SupportedScope struct {
+	Label string `json:"label"`
+	Name  string `json:"name"`
+}
+
+// FieldByName returns the field with the given name, ignoring case, or nil if there isn't one
+func (dr *DescribeResponse) FieldByName(name string) *Field {
+	if i := fieldIndex(dr, name); i >= 0 {
+		return &dr.Fields[i]
 	}
-	var dr DescribeResponse
-	if err := s.client.makeRequest(ctx, req, &dr); err != nil {
-		return nil, err
+	return nil
+}
+
+// FieldByRelationship returns the reference field with the given relationship name, such as
+// AccountId for Account, ignoring case, or nil if there isn't one
+func (dr *DescribeResponse) FieldByRelationship(relationship string) *Field {
+	if i := relationshipIndex(dr, relationship); i >= 0 {
+		return &dr.Fields[i]
 	}
-	return &dr, nil
+	return nil
 }
 
-func (s *ContactService) Describe(ctx context.Context) (*DescribeResponse, error) {
-	sfurl := fmt.Sprintf("%s/services/data/%s/sobjects/Contact/describe", s.client.BaseURL, s.client.Version)
-	req, err := http.NewRequest("GET", sfurl, nil)
-	if err != nil {
-		return nil, err
+// ChildRelationship returns the child relationship with the given name, such as Contacts on
+// Account, ignoring case, or nil if there isn't one
+func (dr *DescribeResponse) ChildRelationship(name string) *ChildRelationship {
+	for i, c := range dr.ChildRelationships {
+		if c.RelationshipName != "" && strings.EqualFold(c.RelationshipName, name) {
+			return &dr.ChildRelationships[i]
+		}
 	}
-	var dr DescribeResponse
-	if err := s.client.makeRequest(ctx, req, &dr); err != nil {
-		return nil, err
+	return nil
+}
+
+// ExternalIDFields returns the fields marked as external IDs
+func (dr *DescribeResponse) ExternalIDFields() []*Field {
+	var fields []*Field
+	for i := range dr.Fields {
+		if dr.Fields[i].ExternalID {
+			fields = append(fields, &dr.Fields[i])
+		}
 	}
-	return &dr, nil
+	return fields
 }
 
-func (s *OpportunityService) Describe(ctx context.Context) (*DescribeResponse, error) {
-	sfurl := fmt.Sprintf("%s/services/data/%s/sobjects/Opportunity/describe", s.client.BaseURL, s.client.Version)
-	req, err := http.NewRequest("GET", sfurl, nil)
-	if err != nil {
-		return nil, err
+// RequiredOnCreate returns the fields that must be given a value when a record is created
+func (dr *DescribeResponse) RequiredOnCreate() []*Field {
+	var fields []*Field
+	for i := range dr.Fields {
+		if dr.Fields[i].RequiredOnCreate() {
+			fields = append(fields, &dr.Fields[i])
+		}
 	}
-	var dr DescribeResponse
-	if err := s.client.makeRequest(ctx, req, &dr); err != nil {
-		return nil, err
+	return fields
+}
+
+// Relationships returns the reference fields that have a relationship name, which can be used
+// to refer to fields on the related object, e.g. Account.Name
+func (dr *DescribeResponse) Relationships() []*Field {
+	var fields []*Field
+	for i := range dr.Fields {
+		if dr.Fields[i].RelationshipName != "" {
+			fields = append(fields, &dr.Fields[i])
+		}
 	}
-	return &dr, nil
+	return fields
 }
 
-func (s *UserService) Describe(ctx context.Context) (*DescribeResponse, error) {
-	sfurl := fmt.Sprintf("%s/services/data/%s/sobjects/User/describe", s.client.BaseURL, s.client.Version)
-	req, err := http.NewRequest("GET", sfurl, nil)
-	if err != nil {
-		return nil, err
+// fieldIndex returns the index of the named field, ignoring case, or -1 if it doesn't exist
+func fieldIndex(dr *DescribeResponse, name string) int {
+	for i, f := range dr.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return i
+		}
 	}
-	var dr DescribeResponse
-	if err := s.client.makeRequest(ctx, req, &dr); err != nil {
+	return -1
+}
+
+// relationshipIndex returns the index of the reference field with the given relationship name, or -1 if it doesn't exist
+func relationshipIndex(dr *DescribeResponse, relationship string) int {
+	for i, f := range dr.Fields {
+		if f.RelationshipName != "" && strings.EqualFold(f.RelationshipName, relationship) {
+			return i
+		}
+	}
+	return -1
+}
+
+// DescribeGlobalResponse lists the objects available in the org
+type DescribeGlobalResponse struct {
+	Encoding     string        `json:"encoding"`
+	MaxBatchSize int           `json:"maxBatchSize"`
+	SObjects     []SObjectInfo `json:"sobjects"`
+}
+
+// SObjectInfo is the summary of an object returned by DescribeGlobal
+type SObjectInfo struct {
+	Activateable        bool   `json:"activateable"`
+	Createable          bool   `json:"createable"`
+	Custom              bool   `json:"custom"`
+	CustomSetting       bool   `json:"customSetting"`
+	Deletable           bool   `json:"deletable"`
+	DeprecatedAndHidden bool   `json:"deprecatedAndHidden"`
+	FeedEnabled         bool   `json:"feedEnabled"`
+	HasSubtypes         bool   `json:"hasSubtypes"`
+	IsInterface         bool   `json:"isInterface"`
+	IsSubtype           bool   `json:"isSubtype"`
+	KeyPrefix           string `json:"keyPrefix"`
+	Label               string `json:"label"`
+	LabelPlural         string `json:"labelPlural"`
+	Layoutable          bool   `json:"layoutable"`
+	Mergeable           bool   `json:"mergeable"`
+	MruEnabled          bool   `json:"mruEnabled"`
+	Name                string `json:"name"`
+	Queryable           bool   `json:"queryable"`
+	Replicateable       bool   `json:"replicateable"`
+	Retrieveable        bool   `json:"retrieveable"`
+	Searchable          bool   `json:"searchable"`
+	Triggerable         bool   `json:"triggerable"`
+	Undeletable         bool   `json:"undeletable"`
+	Updateable          bool   `json:"updateable"`
+	Urls                struct {
+		Describe    string `json:"describe"`
+		Sobject     string `json:"sobject"`
+		RowTemplate string `json:"rowTemplate"`
+	} `json:"urls"`
+}
+
+// Object returns the object with the given name, ignoring case, or nil
+func (dg *DescribeGlobalResponse) Object(name string) *SObjectInfo {
+	for i := range dg.SObjects {
+		if strings.EqualFold(dg.SObjects[i].Name, name) {
+			return &dg.SObjects[i]
+		}
+	}
+	return nil
+}
+
+// ObjectForID returns the object whose key prefix matches the first three characters of a record
+// id, or nil if no object has that prefix
+func (dg *DescribeGlobalResponse) ObjectForID(id string) *SObjectInfo {
+	if len(id) < 3 {
+		return nil
+	}
+	for i := range dg.SObjects {
+		if dg.SObjects[i].KeyPrefix == id[:3] {
+			return &dg.SObjects[i]
+		}
+	}
+	return nil
+}
+
+// DescribeGlobal lists the objects available in the org, with their key prefixes and what can be done with them
+func (c *Client) DescribeGlobal(ctx context.Context) (*DescribeGlobalResponse, error) {
+	var dg DescribeGlobalResponse
+	if err := c.getDescribe(ctx, "/sobjects", "global", &dg); err != nil {
 		return nil, err
 	}
-	return &dr, nil
+	return &dg, nil
 }
 
+// Describe returns the metadata of the Account object
+func (s *AccountService) Describe(ctx context.Context) (*DescribeResponse, error) {
+	return s.client.Describe(ctx, "Account")
+}
+
+// Describe returns the metadata of the Contact object
+func (s *ContactService) Describe(ctx context.Context) (*DescribeResponse, error) {
+	return s.client.Describe(ctx, "Contact")
+}
+
+// Describe returns the metadata of the Opportunity object
+func (s *OpportunityService) Describe(ctx context.Context) (*DescribeResponse, error) {
+	return s.client.Describe(ctx, "Opportunity")
+}
+
+// Describe returns the metadata of the User object
+func (s *UserService) Describe(ctx context.Context) (*DescribeResponse, error) {
+	return s.client.Describe(ctx, "User")
+}
+
+// Describe returns the metadata of an object, using the describe cache if the client has one
 func (c *Client) Describe(ctx context.Context, object string) (*DescribeResponse, error) {
 	if object == "" {
 		return nil, errors.New("object required for description")
 	}
-	sfurl := fmt.Sprintf("%s/services/data/%s/sobjects/%s/describe", c.BaseURL, c.Version, object)
-	req, err := http.NewRequest("GET", sfurl, nil)
-	if err != nil {
-		return nil, err
-	}
 	var dr DescribeResponse
-	if err := c.makeRequest(ctx, req, &dr); err != nil {
+	if err := c.getDescribe(ctx, "/sobjects/"+url.PathEscape(object)+"/describe", describeKey(object), &dr); err != nil {
 		return nil, err
 	}
 	return &dr, nil
 }
+
+// RelationshipColumn splits a bulk CSV column that refers to a related record, such as
+// Account.ERP_Id__c or, for a polymorphic relationship, User:Owner.Username.  Ok is false if the
+// column isn't a relationship column.
+func RelationshipColumn(column string) (objectType, relationship, field string, ok bool) {
+	dot := strings.Index(column, ".")
+	if dot < 0 {
+		return "", "", "", false
+	}
+	path := column
+	if colon := strings.Index(column[:dot], ":"); colon >= 0 {
+		objectType, path = column[:colon], column[colon+1:]
+		dot -= colon + 1
+	}
+	relationship, field = path[:dot], path[dot+1:]
+	return objectType, relationship, field, relationship != "" && field != ""
+}
+
+// References returns the objects that the columns in a bulk CSV header refer to, either directly
+// with a reference field such as AccountId or through a relationship column such as Account.ERP_Id__c
+func (dr *DescribeResponse) References(header []string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	add := func(objects ...string) {
+		for _, o := range objects {
+			if !seen[strings.ToLower(o)] {
+				seen[strings.ToLower(o)] = true
+				refs = append(refs, o)
+			}
+		}
+	}
+	for _, column := range header {
+		if objectType, relationship, _, ok := RelationshipColumn(column); ok {
+			idx := relationshipIndex(dr, relationship)
+			if idx < 0 {
+				continue
+			}
+			if objectType != "" {
+				add(objectType)
+			} else {
+				add(dr.Fields[idx].ReferenceTo...)
+			}
+			continue
+		}
+		if idx := fieldIndex(dr, column); idx >= 0 && dr.Fields[idx].Type == "reference" {
+			add(dr.Fields[idx].ReferenceTo...)
+		}
+	}
+	return refs
+}
@@ -16,6 +16,7 @@ const (
 	ErrBadRequest               = Err("salesforce: bad request")
 	ErrUnauthorized             = Err("salesforce: unauthorized request")
 	ErrForbidden                = Err("salesforce: forbidden")
+	ErrNotFound                 = Err("salesforce: the requested resource does not exist")           // 404
 	ErrMethodNotAllowed         = Err("salesforce: method not allowed")                              // 405
 	ErrConflict                 = Err("salesforce: conflict with the current state of the resource") // 409
 	ErrInternalError            = Err("salesforce: internal error")
@@ -0,0 +1,52 @@
+package salesforce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ExplainResponse holds the query plans salesforce considered for a query, report or list view
+type ExplainResponse struct {
+	Plans       []QueryPlan `json:"plans"`
+	SourceQuery string      `json:"sourceQuery"`
+}
+
+// QueryPlan is one of the ways salesforce could run a query.  A relative cost above 1 means the
+// query isn't selective, and the plan with the lowest cost is the one that will be used.
+type QueryPlan struct {
+	Cardinality          int             `json:"cardinality"`
+	Fields               []string        `json:"fields"`
+	LeadingOperationType string          `json:"leadingOperationType"`
+	Notes                []QueryPlanNote `json:"notes"`
+	RelativeCost         float64         `json:"relativeCost"`
+	SobjectCardinality   int             `json:"sobjectCardinality"`
+	SobjectType          string          `json:"sobjectType"`
+}
+
+// QueryPlanNote explains why a plan couldn't use an index, e.g. because a field isn't indexed
+type QueryPlanNote struct {
+	Description   string   `json:"description"`
+	Fields        []string `json:"fields"`
+	TableEnumOrID string   `json:"tableEnumOrId"`
+}
+
+// Explain returns the query plans for a SOQL query without running it.  The id of a report or
+// list view can be given instead of a query.
+func (c *Client) Explain(ctx context.Context, soql string) (*ExplainResponse, error) {
+	if soql == "" {
+		return nil, errors.New("query required")
+	}
+	sfurl := fmt.Sprintf("%s/services/data/%s/query?explain=%s", c.BaseURL, c.Version, url.QueryEscape(soql))
+	req, err := http.NewRequest("GET", sfurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	var er ExplainResponse
+	if err := c.makeRequest(ctx, req, &er); err != nil {
+		return nil, err
+	}
+	return &er, nil
+}
@@ -0,0 +1,394 @@
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Limits of the composite graph resource
+const (
+	// MaxGraphNodes is the most nodes in a composite graph request, across all of its graphs
+	MaxGraphNodes = 500
+	// MaxGraphDepth is the longest chain of nodes that refer to each other in a graph
+	MaxGraphDepth = 15
+)
+
+// Graph builds a composite graph, a set of record operations that are committed together, so a
+// failure in any of them rolls back the whole graph.  Each node has a key chosen by the caller,
+// and a node can refer to a field of another node's result with Ref, e.g.
+//
+//	g := salesforce.NewGraph()
+//	g.Create("acme", "Account", map[string]interface{}{"Name": "Acme"})
+//	g.Create("jane", "Contact", map[string]interface{}{"LastName": "Smith", "AccountId": g.Ref("acme", "id")})
+//	g.Create("deal", "Opportunity", map[string]interface{}{"Name": "Renewal", "StageName": "Prospecting",
+//		"CloseDate": "2024-06-30", "AccountId": g.Ref("acme", "id")})
+//	g.Create("line", "OpportunityLineItem", map[string]interface{}{"OpportunityId": g.Ref("deal", "id"),
+//		"PricebookEntryId": entryID, "Quantity": 10})
+//	res, err := sc.CompositeService.Graph(ctx, g)
+//
+// Nodes can be added in any order, since they are sorted so that every node comes after the
+// nodes it refers to.  Errors, such as a reference to a missing node or a cycle, are returned
+// by Validate and when the graph is submitted.
+type Graph struct {
+	// ID identifies the graph in the request.  One is generated if it is empty.
+	ID string
+
+	nodes []*graphNode
+	keys  map[string]*graphNode
+	err   error
+}
+
+// graphNode is a single operation in a graph
+type graphNode struct {
+	key      string
+	method   string
+	resource string // relative to /services/data/vXX.X
+	body     interface{}
+}
+
+// graphRef matches a reference to another node's result in a url or body, capturing the key
+var graphRef = regexp.MustCompile(`@\{([^.{}"\\]+)\.`)
+
+// NewGraph returns an empty graph
+func NewGraph() *Graph {
+	return &Graph{keys: make(map[string]*graphNode)}
+}
+
+// Ref returns a reference to a field of the result of the node with the given key, such as
+// its id, for use in the fields or id of another node
+func (g *Graph) Ref(key, field string) string {
+	return fmt.Sprintf("@{%s.%s}", key, field)
+}
+
+// Create adds a node that creates a record from the fields, which can be a map of field names to
+// values or a struct that encodes to JSON with the field names
+func (g *Graph) Create(key, object string, fields interface{}) *Graph {
+	return g.add(key, "POST", "/sobjects/"+url.PathEscape(object), fields)
+}
+
+// Update adds a node that updates the fields of a record.  The id can be a reference.
+func (g *Graph) Update(key, object, id string, fields interface{}) *Graph {
+	return g.add(key, "PATCH", fmt.Sprintf("/sobjects/%s/%s", url.PathEscape(object), pathValue(id)), fields)
+}
+
+// Upsert adds a node that creates or updates the record with the given value in an external ID field
+func (g *Graph) Upsert(key, object, field, value string, fields interface{}) *Graph {
+	return g.add(key, "PATCH", fmt.Sprintf("/sobjects/%s/%s/%s", url.PathEscape(object), url.PathEscape(field), pathValue(value)), fields)
+}
+
+// Delete adds a node that deletes a record.  The id can be a reference.
+func (g *Graph) Delete(key, object, id string) *Graph {
+	return g.add(key, "DELETE", fmt.Sprintf("/sobjects/%s/%s", url.PathEscape(object), pathValue(id)), nil)
+}
+
+// pathValue escapes a value for a url path, unless it is a reference to another node
+func pathValue(s string) string {
+	if strings.HasPrefix(s, "@{") && strings.HasSuffix(s, "}") {
+		return s
+	}
+	return url.PathEscape(s)
+}
+
+func (g *Graph) add(key, method, resource string, body interface{}) *Graph {
+	if g.err != nil {
+		return g
+	}
+	if g.keys == nil {
+		g.keys = make(map[string]*graphNode)
+	}
+	switch {
+	case key == "":
+		g.err = errors.New("graph: node key required")
+	case strings.ContainsAny(key, `.{}"\`):
+		g.err = fmt.Errorf("graph: node key %q can't contain . { } \" or \\", key)
+	case g.keys[key] != nil:
+		g.err = fmt.Errorf("graph: duplicate node key %s", key)
+	}
+	if g.err != nil {
+		return g
+	}
+	n := &graphNode{key: key, method: method, resource: resource, body: body}
+	g.nodes = append(g.nodes, n)
+	g.keys[key] = n
+	return g
+}
+
+// Len returns the number of nodes in the graph
+func (g *Graph) Len() int {
+	return len(g.nodes)
+}
+
+// Validate checks that the graph is within the limits, every reference is to a node in the
+// graph and that there are no cycles
+func (g *Graph) Validate() error {
+	_, err := g.compile("")
+	return err
+}
+
+// compile sorts the nodes so each comes after the nodes it refers to and converts them to the
+// subrequests of the graph.  The keys are replaced by reference ids that salesforce accepts,
+// returning the keys by reference id.
+func (g *Graph) compile(version string) (*compiledGraph, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	if len(g.nodes) == 0 {
+		return nil, errors.New("graph: no nodes")
+	}
+	if len(g.nodes) > MaxGraphNodes {
+		return nil, fmt.Errorf("graph: too many nodes: %d, the limit is %d", len(g.nodes), MaxGraphNodes)
+	}
+
+	refIDs := make(map[string]string, len(g.nodes))
+	keys := make(map[string]string, len(g.nodes))
+	for i, n := range g.nodes {
+		ref := fmt.Sprintf("node%d", i+1)
+		refIDs[n.key] = ref
+		keys[ref] = n.key
+	}
+
+	// encode the bodies and find the nodes each one refers to
+	bodies := make(map[string]string, len(g.nodes))
+	deps := make(map[string][]string, len(g.nodes))
+	for _, n := range g.nodes {
+		var body string
+		if n.body != nil {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(n.body); err != nil {
+				return nil, fmt.Errorf("graph: node %s: %w", n.key, err)
+			}
+			body = strings.TrimSpace(buf.String())
+		}
+		bodies[n.key] = body
+		seen := make(map[string]bool)
+		for _, m := range graphRef.FindAllStringSubmatch(n.resource+body, -1) {
+			dep := m[1]
+			if g.keys[dep] == nil {
+				return nil, fmt.Errorf("graph: node %s refers to unknown node %s", n.key, dep)
+			}
+			if dep == n.key {
+				return nil, fmt.Errorf("graph: node %s refers to itself", n.key)
+			}
+			if !seen[dep] {
+				seen[dep] = true
+				deps[n.key] = append(deps[n.key], dep)
+			}
+		}
+	}
+
+	// sort the nodes, keeping the order they were added where possible
+	depth := make(map[string]int, len(g.nodes))
+	var sorted []*graphNode
+	for len(sorted) < len(g.nodes) {
+		progress := false
+		for _, n := range g.nodes {
+			if depth[n.key] > 0 {
+				continue
+			}
+			d, ready := 1, true
+			for _, dep := range deps[n.key] {
+				if depth[dep] == 0 {
+					ready = false
+					break
+				}
+				if depth[dep]+1 > d {
+					d = depth[dep] + 1
+				}
+			}
+			if !ready {
+				continue
+			}
+			if d > MaxGraphDepth {
+				return nil, fmt.Errorf("graph: node %s is %d levels deep, the limit is %d", n.key, d, MaxGraphDepth)
+			}
+			depth[n.key] = d
+			sorted = append(sorted, n)
+			progress = true
+		}
+		if !progress {
+			var cycle []string
+			for _, n := range g.nodes {
+				if depth[n.key] == 0 {
+					cycle = append(cycle, n.key)
+				}
+			}
+			return nil, fmt.Errorf("graph: nodes refer to each other in a cycle: %s", strings.Join(cycle, ", "))
+		}
+	}
+
+	replace := func(s string) string {
+		return graphRef.ReplaceAllStringFunc(s, func(m string) string {
+			key := m[2 : len(m)-1]
+			return "@{" + refIDs[key] + "."
+		})
+	}
+	cg := &compiledGraph{keys: keys}
+	for _, n := range sorted {
+		sr := CompositeSubrequest{
+			Method:      n.method,
+			URL:         replace(fmt.Sprintf("/services/data/%s%s", version, n.resource)),
+			ReferenceID: refIDs[n.key],
+		}
+		if body := bodies[n.key]; body != "" {
+			sr.Body = json.RawMessage(replace(body))
+		}
+		cg.requests = append(cg.requests, sr)
+	}
+	return cg, nil
+}
+
+// compiledGraph is a graph ready to be submitted
+type compiledGraph struct {
+	requests []CompositeSubrequest
+	keys     map[string]string // the caller's keys by reference id
+}
+
+// GraphResult is the outcome of a graph
+type GraphResult struct {
+	GraphID string
+	// Successful is set when every node succeeded.  Otherwise none of them were committed.
+	Successful bool
+	// Keys lists the caller's keys in the order the nodes were sent, which is the order they
+	// were added except where a node had to be moved after the nodes it refers to
+	Keys []string
+	// Nodes holds the response to each node by the caller's key
+	Nodes map[string]*CompositeSubresponse
+}
+
+// ID returns the id of the record created or upserted by the node with the given key, or an
+// empty string if there isn't one
+func (gr *GraphResult) ID(key string) string {
+	n := gr.Nodes[key]
+	if n == nil {
+		return ""
+	}
+	var sr SaveResult
+	if err := n.Decode(&sr); err != nil {
+		return ""
+	}
+	return sr.ID
+}
+
+// Err returns the error that caused the graph to fail, if it did.  Once a node fails, salesforce
+// halts the others, so their errors are only reported if no other cause is found.
+func (gr *GraphResult) Err() error {
+	if gr.Successful {
+		return nil
+	}
+	var halted error
+	for _, key := range gr.Keys {
+		n := gr.Nodes[key]
+		if n == nil {
+			continue
+		}
+		err := n.Err()
+		if err == nil {
+			continue
+		}
+		var errs []BadRequestError
+		if json.Unmarshal(n.Body, &errs) == nil && len(errs) > 0 && errs[0].ErrorCode == "PROCESSING_HALTED" {
+			if halted == nil {
+				halted = fmt.Errorf("%s: %w", key, err)
+			}
+			continue
+		}
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if halted != nil {
+		return halted
+	}
+	return fmt.Errorf("graph %s failed", gr.GraphID)
+}
+
+// Graph submits a single graph.  Check the Err method of the result for the reason it failed.
+func (s *CompositeService) Graph(ctx context.Context, g *Graph) (*GraphResult, error) {
+	res, err := s.Graphs(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+// Graphs submits several graphs in a single call.  Each graph is committed or rolled back on its
+// own, and the results are in the same order as the graphs.
+func (s *CompositeService) Graphs(ctx context.Context, graphs ...*Graph) ([]*GraphResult, error) {
+	if len(graphs) == 0 {
+		return nil, errors.New("graphs required")
+	}
+	type graphRequest struct {
+		GraphID          string                `json:"graphId"`
+		CompositeRequest []CompositeSubrequest `json:"compositeRequest"`
+	}
+	var payload struct {
+		Graphs []graphRequest `json:"graphs"`
+	}
+	compiled := make(map[string]*compiledGraph, len(graphs))
+	ids := make([]string, len(graphs))
+	nodes := 0
+	for i, g := range graphs {
+		cg, err := g.compile(s.client.Version)
+		if err != nil {
+			return nil, err
+		}
+		nodes += len(cg.requests)
+		ids[i] = g.ID
+		if ids[i] == "" {
+			ids[i] = fmt.Sprintf("graph%d", i+1)
+		}
+		if compiled[ids[i]] != nil {
+			return nil, fmt.Errorf("duplicate graph id %s", ids[i])
+		}
+		compiled[ids[i]] = cg
+		payload.Graphs = append(payload.Graphs, graphRequest{GraphID: ids[i], CompositeRequest: cg.requests})
+	}
+	if nodes > MaxGraphNodes {
+		return nil, fmt.Errorf("too many nodes: %d, the limit is %d", nodes, MaxGraphNodes)
+	}
+
+	var res struct {
+		Graphs []struct {
+			GraphID       string            `json:"graphId"`
+			GraphResponse CompositeResponse `json:"graphResponse"`
+			IsSuccessful  bool              `json:"isSuccessful"`
+		} `json:"graphs"`
+	}
+	if err := s.client.post(ctx, s.url("/graph"), payload, &res); err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*GraphResult, len(res.Graphs))
+	for _, r := range res.Graphs {
+		cg := compiled[r.GraphID]
+		if cg == nil {
+			continue
+		}
+		gr := &GraphResult{GraphID: r.GraphID, Successful: r.IsSuccessful, Nodes: make(map[string]*CompositeSubresponse)}
+		responses := make(map[string]*CompositeSubresponse, len(r.GraphResponse.CompositeResponse))
+		for i := range r.GraphResponse.CompositeResponse {
+			sr := &r.GraphResponse.CompositeResponse[i]
+			responses[sr.ReferenceID] = sr
+		}
+		for _, req := range cg.requests {
+			key := cg.keys[req.ReferenceID]
+			gr.Keys = append(gr.Keys, key)
+			if sr := responses[req.ReferenceID]; sr != nil {
+				gr.Nodes[key] = sr
+			}
+		}
+		byID[r.GraphID] = gr
+	}
+	results := make([]*GraphResult, len(graphs))
+	for i, id := range ids {
+		if results[i] = byID[id]; results[i] == nil {
+			return nil, fmt.Errorf("no result for graph %s", id)
+		}
+	}
+	return results, nil
+}
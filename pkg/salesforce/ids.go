@@ -0,0 +1,75 @@
+package salesforce
+
+import (
+	"fmt"
+	"strings"
+)
+
+// idSuffixChars encode which of the five characters in each block of a 15 character id are upper case
+const idSuffixChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ012345"
+
+// ID18 returns the case-insensitive 18 character form of a record id.  An 18 character id is
+// returned with the casing of its first 15 characters restored from its suffix.
+func ID18(id string) (string, error) {
+	id15, err := ID15(id)
+	if err != nil {
+		return "", err
+	}
+	suffix := make([]byte, 3)
+	for block := 0; block < 3; block++ {
+		flags := 0
+		for i := 0; i < 5; i++ {
+			if c := id15[block*5+i]; c >= 'A' && c <= 'Z' {
+				flags |= 1 << i
+			}
+		}
+		suffix[block] = idSuffixChars[flags]
+	}
+	return id15 + string(suffix), nil
+}
+
+// ID15 returns the case-sensitive 15 character form of a record id.  The casing of an 18 character
+// id is taken from its suffix, so ids that have been upper or lower cased are converted correctly.
+func ID15(id string) (string, error) {
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return "", fmt.Errorf("invalid id %s: ids can only contain letters and digits", id)
+		}
+	}
+	switch len(id) {
+	case 15:
+		return id, nil
+	case 18:
+	default:
+		return "", fmt.Errorf("invalid id %s: ids have 15 or 18 characters", id)
+	}
+	b := []byte(id[:15])
+	for block := 0; block < 3; block++ {
+		flags := strings.IndexByte(idSuffixChars, upper(id[15+block]))
+		if flags < 0 {
+			return "", fmt.Errorf("invalid id %s: the suffix %s isn't valid", id, id[15:])
+		}
+		for i := 0; i < 5; i++ {
+			if flags&(1<<i) != 0 {
+				b[block*5+i] = upper(b[block*5+i])
+			} else {
+				b[block*5+i] = lower(b[block*5+i])
+			}
+		}
+	}
+	return string(b), nil
+}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+func lower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c - 'A' + 'a'
+	}
+	return c
+}
@@ -0,0 +1,67 @@
+package salesforce
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestID18(t *testing.T) {
+	tests := []struct {
+		id15 string
+		id18 string
+	}{
+		{"001D000000IqhSL", "001D000000IqhSLIAZ"},
+		{"001D000000IRFma", "001D000000IRFmaIAH"},
+		{"003000000000000", "003000000000000AAA"},
+		{"ABCDEABCDEABCDE", "ABCDEABCDEABCDE555"},
+		{"a0B5e00000aBcDe", "a0B5e00000aBcDeEAK"},
+	}
+	for _, tt := range tests {
+		got, err := ID18(tt.id15)
+		if err != nil {
+			t.Errorf("ID18(%s): %s", tt.id15, err)
+		} else if got != tt.id18 {
+			t.Errorf("ID18(%s) = %s, want %s", tt.id15, got, tt.id18)
+		}
+		for _, id := range []string{tt.id18, strings.ToUpper(tt.id18), strings.ToLower(tt.id18)} {
+			if got, err := ID15(id); err != nil {
+				t.Errorf("ID15(%s): %s", id, err)
+			} else if got != tt.id15 {
+				t.Errorf("ID15(%s) = %s, want %s", id, got, tt.id15)
+			}
+			if got, err := ID18(id); err != nil {
+				t.Errorf("ID18(%s): %s", id, err)
+			} else if got != tt.id18 {
+				t.Errorf("ID18(%s) = %s, want %s", id, got, tt.id18)
+			}
+		}
+		if got, err := ID15(tt.id15); err != nil || got != tt.id15 {
+			t.Errorf("ID15(%s) = %s, %v, want it unchanged", tt.id15, got, err)
+		}
+	}
+}
+
+func TestIDErrors(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"", "15 or 18 characters"},
+		{"001D000000IqhS", "15 or 18 characters"},
+		{"001D000000IqhSLIA", "15 or 18 characters"},
+		{"001D000000IqhSL-AZ", "letters and digits"},
+		{"001D000000IqhSLIA9", "suffix IA9 isn't valid"},
+		{"001D000000IqhSL6AZ", "suffix 6AZ isn't valid"},
+		{"001D000000IqhSLé", "letters and digits"},
+	}
+	for _, tt := range tests {
+		for name, fn := range map[string]func(string) (string, error){"ID15": ID15, "ID18": ID18} {
+			got, err := fn(tt.id)
+			if err == nil {
+				t.Errorf("%s(%q) = %s, expected an error", name, tt.id, got)
+			} else if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("%s(%q): got error %q, want it to contain %q", name, tt.id, err, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package salesforce
+
+import (
+	"context"
+	"time"
+)
+
+// Opportunity is a subset of the standard fields of an Opportunity
+type Opportunity struct {
+	ID               string     `sf:"Id" json:"-"`
+	AccountID        string     `sf:"AccountId" json:"AccountId,omitempty"`
+	Name             string     `json:"Name,omitempty"`
+	StageName        string     `json:"StageName,omitempty"`
+	CloseDate        string     `json:"CloseDate,omitempty"` // YYYY-MM-DD
+	Amount           *float64   `json:"Amount,omitempty"`
+	Probability      *float64   `json:"Probability,omitempty"`
+	Type             string     `json:"Type,omitempty"`
+	LeadSource       string     `json:"LeadSource,omitempty"`
+	NextStep         string     `json:"NextStep,omitempty"`
+	OwnerID          string     `sf:"OwnerId" json:"OwnerId,omitempty"`
+	Description      string     `json:"Description,omitempty"`
+	IsClosed         bool       `sf:"IsClosed" json:"-"`
+	IsWon            bool       `sf:"IsWon" json:"-"`
+	CreatedDate      *time.Time `sf:"CreatedDate" json:"-"`
+	LastModifiedDate *time.Time `sf:"LastModifiedDate" json:"-"`
+}
+
+// Get returns the opportunity with the given id
+func (s *OpportunityService) Get(ctx context.Context, id string) (*Opportunity, error) {
+	r, err := s.client.SObjectService.Get(ctx, "Opportunity", id)
+	if err != nil {
+		return nil, err
+	}
+	var o Opportunity
+	if err := r.Decode(&o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Create creates an opportunity, returning its id
+func (s *OpportunityService) Create(ctx context.Context, o *Opportunity) (string, error) {
+	res, err := s.client.SObjectService.Create(ctx, "Opportunity", o)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// Update updates the fields of the opportunity with the given id that are set in o
+func (s *OpportunityService) Update(ctx context.Context, id string, o *Opportunity) error {
+	return s.client.SObjectService.Update(ctx, "Opportunity", id, o)
+}
+
+// Upsert creates or updates the opportunity with the given value in an external ID field
+func (s *OpportunityService) Upsert(ctx context.Context, field, value string, o *Opportunity) (*SaveResult, error) {
+	return s.client.SObjectService.Upsert(ctx, "Opportunity", field, value, o)
+}
+
+// Delete deletes the opportunity with the given id
+func (s *OpportunityService) Delete(ctx context.Context, id string) error {
+	return s.client.SObjectService.Delete(ctx, "Opportunity", id)
+}
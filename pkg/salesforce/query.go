@@ -0,0 +1,276 @@
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// QueryResponse represents a page of results from the query or queryAll endpoints.  Child relationship
+// fields in a record, e.g. from SELECT Name, (SELECT LastName FROM Contacts) FROM Account, are also
+// returned as a QueryResponse.
+type QueryResponse struct {
+	TotalSize      int       `json:"totalSize"`
+	Done           bool      `json:"done"`
+	NextRecordsURL string    `json:"nextRecordsUrl,omitempty"`
+	Records        []*Record `json:"records"`
+}
+
+// RecordAttributes holds the type and url salesforce includes with every record
+type RecordAttributes struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Record is a single record returned by a query.  The fields are kept in the order salesforce returned
+// them, which is the order they were selected in.  Values are nil, string, bool or json.Number, with
+// parent relationships as a *Record and child relationships as a *QueryResponse.
+type Record struct {
+	Attributes RecordAttributes
+	fields     []string
+	values     map[string]interface{}
+}
+
+// Fields returns the names of the fields in the record, in order
+func (r *Record) Fields() []string {
+	return r.fields
+}
+
+// Get returns the value of the named field, ignoring case
+func (r *Record) Get(name string) (interface{}, bool) {
+	if v, ok := r.values[name]; ok {
+		return v, true
+	}
+	for _, f := range r.fields {
+		if strings.EqualFold(f, name) {
+			return r.values[f], true
+		}
+	}
+	return nil, false
+}
+
+// Set sets the value of the named field, adding it to the end of the record if it isn't already present
+func (r *Record) Set(name string, value interface{}) {
+	if r.values == nil {
+		r.values = make(map[string]interface{})
+	}
+	if _, ok := r.values[name]; !ok {
+		r.fields = append(r.fields, name)
+	}
+	r.values[name] = value
+}
+
+// Flatten returns a record with the fields of parent relationships included as Relationship.Field,
+// e.g. Account.Owner.Name.  A parent relationship that is null is returned as a single nil field,
+// and child relationships are returned as a slice of flattened records.
+func (r *Record) Flatten() *Record {
+	flat := &Record{Attributes: r.Attributes}
+	r.flatten("", flat)
+	return flat
+}
+
+func (r *Record) flatten(prefix string, flat *Record) {
+	for _, f := range r.fields {
+		switch v := r.values[f].(type) {
+		case *Record:
+			v.flatten(prefix+f+".", flat)
+		case *QueryResponse:
+			children := make([]*Record, len(v.Records))
+			for i, c := range v.Records {
+				children[i] = c.Flatten()
+			}
+			flat.Set(prefix+f, children)
+		default:
+			flat.Set(prefix+f, v)
+		}
+	}
+}
+
+// UnmarshalJSON decodes a record, keeping the order of the fields
+func (r *Record) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if t, err := dec.Token(); err != nil {
+		return err
+	} else if t != json.Delim('{') {
+		return errors.New("salesforce: record is not an object")
+	}
+	r.fields, r.values = nil, make(map[string]interface{})
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := t.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		if name == "attributes" {
+			if err := json.Unmarshal(raw, &r.Attributes); err != nil {
+				return err
+			}
+			continue
+		}
+		v, err := decodeValue(raw)
+		if err != nil {
+			return fmt.Errorf("salesforce: field %s: %w", name, err)
+		}
+		r.Set(name, v)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// decodeValue decodes a field value, recognising related records and child relationships
+func decodeValue(raw json.RawMessage) (interface{}, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &keys); err != nil {
+			return nil, err
+		}
+		_, hasRecords := keys["records"]
+		_, hasAttributes := keys["attributes"]
+		if hasRecords && !hasAttributes {
+			var qr QueryResponse
+			err := json.Unmarshal(trimmed, &qr)
+			return &qr, err
+		}
+		if hasAttributes {
+			var rec Record
+			err := json.Unmarshal(trimmed, &rec)
+			return &rec, err
+		}
+	}
+	// anything else, such as compound address fields, is decoded as plain JSON
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// MarshalJSON encodes the record with its fields in order, without the attributes
+func (r *Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.values[f])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// QueryIterator returns the records from a query one at a time, fetching the next page of
+// results from salesforce when required, e.g.
+//
+//	it, err := client.Query(ctx, "SELECT Id, Name FROM Account")
+//	if err != nil {
+//		return err
+//	}
+//	for it.Next() {
+//		fmt.Println(it.Record().Get("Name"))
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+//
+// Only the records of the top level query are paged, child relationships contain the first page only.
+type QueryIterator struct {
+	client *Client
+	ctx    context.Context
+	page   *QueryResponse
+	next   int
+	record *Record
+	err    error
+}
+
+// Next moves to the next record, returning false when there are no more records or an error occurred
+func (it *QueryIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.next >= len(it.page.Records) {
+		if it.page.Done || it.page.NextRecordsURL == "" {
+			it.record = nil
+			return false
+		}
+		page, err := it.client.queryPage(it.ctx, it.client.BaseURL+it.page.NextRecordsURL)
+		if err != nil {
+			it.err, it.record = err, nil
+			return false
+		}
+		it.page, it.next = page, 0
+	}
+	it.record = it.page.Records[it.next]
+	it.next++
+	return true
+}
+
+// Record returns the current record
+func (it *QueryIterator) Record() *Record {
+	return it.record
+}
+
+// Err returns the error, if any, that stopped the iteration
+func (it *QueryIterator) Err() error {
+	return it.err
+}
+
+// TotalSize returns the total number of records the query matched
+func (it *QueryIterator) TotalSize() int {
+	return it.page.TotalSize
+}
+
+// Query runs a SOQL query and returns an iterator over the records.  The first page of
+// results is fetched straight away so that errors in the query are returned here.
+func (c *Client) Query(ctx context.Context, soql string) (*QueryIterator, error) {
+	return c.query(ctx, "query", soql)
+}
+
+// QueryAll is like Query, but includes records that have been deleted or archived
+func (c *Client) QueryAll(ctx context.Context, soql string) (*QueryIterator, error) {
+	return c.query(ctx, "queryAll", soql)
+}
+
+func (c *Client) query(ctx context.Context, endpoint, soql string) (*QueryIterator, error) {
+	if soql == "" {
+		return nil, errors.New("query required")
+	}
+	sfurl := fmt.Sprintf("%s/services/data/%s/%s?q=%s", c.BaseURL, c.Version, endpoint, url.QueryEscape(soql))
+	page, err := c.queryPage(ctx, sfurl)
+	if err != nil {
+		return nil, err
+	}
+	return &QueryIterator{client: c, ctx: ctx, page: page}, nil
+}
+
+func (c *Client) queryPage(ctx context.Context, sfurl string) (*QueryResponse, error) {
+	req, err := http.NewRequest("GET", sfurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	var qr QueryResponse
+	if err := c.makeRequest(ctx, req, &qr); err != nil {
+		return nil, err
+	}
+	return &qr, nil
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -34,6 +36,10 @@ type Client struct {
 
 	//HTTP Client to use for making requests, allowing the user to supply their own if required.
 	HTTPClient *http.Client
+	// StreamClient is used for uploads and downloads of bulk data, which can take much longer than
+	// other requests, so it shouldn't have an overall Timeout.  It is the same as HTTPClient if
+	// you provide your own.
+	StreamClient *http.Client
 
 	// BulkService represents the Bulk 2.0 API
 	BulkService *BulkService
@@ -45,12 +51,20 @@ type Client struct {
 	OpportunityService *OpportunityService
 	// UserService represents the User object
 	UserService *UserService
+	// SObjectService works with single records of any object
+	SObjectService *SObjectService
+	// CompositeService makes several requests in a single call
+	CompositeService *CompositeService
+
+	// DescribeCache, if set, keeps describe results so they are only downloaded when they change
+	DescribeCache DescribeCache
 
 	username string
 	password string
 	clientID string
 	secret   string
 	token    *sftoken
+	tokenMu  sync.Mutex // guards token so the client can be shared between goroutines
 	lim      *rate.Limiter
 }
 
@@ -79,6 +93,19 @@ type UserService struct {
 	client *Client
 }
 
+// SObjectService works with single records of any object.  The Account, Contact, Opportunity
+// and User structs can be passed to Create and Update: fields that can't be written are only
+// decoded, and empty fields aren't sent, so use Update with a map and a nil value to clear a
+// field or set one to false.
+type SObjectService struct {
+	client *Client
+}
+
+// CompositeService represents the composite resources, which make several requests in a single call
+type CompositeService struct {
+	client *Client
+}
+
 // NewClient is a helper function that returns an new salesforce client given the required parameters.
 // Optionally you can provide your own http client or use nil to use the default.  This is done to
 // ensure you're aware of the decision you're making to not provide your own http client.
@@ -86,27 +113,32 @@ func NewClient(baseURL, username, password, clientID, secret string, client *htt
 	if baseURL == "" || username == "" || password == "" || clientID == "" || secret == "" {
 		return nil, errors.New("missing required parameters")
 	}
+	streamClient := client
 	if client == nil {
 		client = &http.Client{
 			Timeout: 10 * time.Second,
 		}
+		streamClient = newStreamClient()
 	}
 	rl := rate.NewLimiter(150, 1) // TODO: Identify what this should be
 	c := &Client{
-		BaseURL:    baseURL,
-		Version:    "v53.0",
-		HTTPClient: client,
-		username:   username,
-		password:   password,
-		clientID:   clientID,
-		secret:     secret,
-		lim:        rl,
+		BaseURL:      baseURL,
+		Version:      "v53.0",
+		HTTPClient:   client,
+		StreamClient: streamClient,
+		username:     username,
+		password:     password,
+		clientID:     clientID,
+		secret:       secret,
+		lim:          rl,
 	}
 	c.BulkService = &BulkService{client: c}
 	c.AccountService = &AccountService{client: c}
 	c.ContactService = &ContactService{client: c}
 	c.OpportunityService = &OpportunityService{client: c}
 	c.UserService = &UserService{client: c}
+	c.SObjectService = &SObjectService{client: c}
+	c.CompositeService = &CompositeService{client: c}
 	return c, nil
 }
 
@@ -132,9 +164,73 @@ func String(v string) *string { return &v }
 
 // makeRequest provides a single function to add common items to the request.
 func (c *Client) makeRequest(ctx context.Context, req *http.Request, v interface{}) error {
+	res, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNoContent || v == nil {
+		return nil
+	}
+
+	// created responses have a body too, such as the id of a new record, but uploads don't
+	if req.Header.Get("Accept") == "application/json" {
+		if err = json.NewDecoder(res.Body).Decode(v); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	if req.Header.Get("Accept") == "text/csv" {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		if p, ok := v.(*string); ok {
+			*p = string(body)
+		}
+	}
+
+	return nil
+}
+
+// makeStreamRequest is the same as makeRequest, but sends the request with the StreamClient and
+// returns the response body rather than decoding it.  The caller is responsible for closing the body.
+func (c *Client) makeStreamRequest(ctx context.Context, req *http.Request) (io.ReadCloser, error) {
+	res, err := c.doStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// newStreamClient returns a client for streamed requests.  Rather than limiting how long the whole
+// request takes, it limits how long connecting and waiting for the response headers can take, so
+// a stalled server is still noticed.
+func newStreamClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSHandshakeTimeout = 10 * time.Second
+	t.ResponseHeaderTimeout = 2 * time.Minute
+	return &http.Client{Transport: t}
+}
+
+// do adds the common items to the request and sends it, converting any unsuccessful response to an error.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.send(ctx, c.HTTPClient, req)
+}
+
+// doStream is the same as do, but sends the request with the StreamClient
+func (c *Client) doStream(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.StreamClient == nil {
+		return c.do(ctx, req)
+	}
+	return c.send(ctx, c.StreamClient, req)
+}
+
+// send makes the request with the given client
+func (c *Client) send(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
 	token, err := c.getToken()
 	if err != nil {
-		return fmt.Errorf("error getting token: %w", err)
+		return nil, fmt.Errorf("error getting token: %w", err)
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
 	if req.Header.Get("Accept") == "" {
@@ -149,32 +245,45 @@ func (c *Client) makeRequest(ctx context.Context, req *http.Request, v interface
 	}
 
 	rc := req.WithContext(ctx)
-	res, err := c.HTTPClient.Do(rc)
+	res, err := client.Do(rc)
 	if err != nil {
-		return fmt.Errorf("error with do: %w", err)
+		return nil, fmt.Errorf("error with do: %w", err)
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+	// 300 is returned when an external ID matches more than one record, so anything other
+	// than success is an error
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		defer res.Body.Close()
 
 		var salesforceErr error
 
 		switch res.StatusCode {
 		case 300:
 			salesforceErr = ErrMultipleExternalIDMatch
+			var matches []string
+			if err = json.NewDecoder(res.Body).Decode(&matches); err == nil {
+				salesforceErr = fmt.Errorf("%w: %s", salesforceErr, strings.Join(matches, ", "))
+			}
 		case 304:
 			salesforceErr = ErrRequestContentNotChanged
 		case 400:
 			salesforceErr = ErrBadRequest
+			body, _ := ioutil.ReadAll(res.Body)
 			var sfbre []BadRequestError
-			if err = json.NewDecoder(res.Body).Decode(&sfbre); err == nil {
+			var tree TreeResponse
+			if err = json.Unmarshal(body, &sfbre); err == nil && len(sfbre) > 0 {
 				fields := strings.Join(sfbre[0].Fields, ",")
 				salesforceErr = fmt.Errorf("%w: %s %s", salesforceErr, sfbre[0].Message, fields)
+			} else if err = json.Unmarshal(body, &tree); err == nil && tree.HasErrors {
+				// composite tree requests list the errors for each record
+				salesforceErr = &TreeError{Results: tree.Results}
 			}
 		case 401:
 			salesforceErr = ErrUnauthorized
 		case 403:
 			salesforceErr = ErrForbidden
+		case 404:
+			salesforceErr = ErrNotFound
 		case 405:
 			salesforceErr = ErrMethodNotAllowed
 		case 409:
@@ -186,39 +295,16 @@ func (c *Client) makeRequest(ctx context.Context, req *http.Request, v interface
 		}
 
 		var sfbre []BadRequestError
-		if err = json.NewDecoder(res.Body).Decode(&sfbre); err == nil {
+		if err = json.NewDecoder(res.Body).Decode(&sfbre); err == nil && len(sfbre) > 0 {
 			fields := strings.Join(sfbre[0].Fields, ",")
 			salesforceErr = fmt.Errorf("%w: %s %s", salesforceErr, sfbre[0].Message, fields)
 		}
 
-		return salesforceErr
-
-	}
+		return nil, salesforceErr
 
-	if res.StatusCode == http.StatusCreated {
-		return nil
 	}
 
-	if res.StatusCode == http.StatusNoContent {
-		return nil
-	}
-
-	if req.Header.Get("Accept") == "application/json" {
-		if err = json.NewDecoder(res.Body).Decode(&v); err != nil {
-			return err
-		}
-	}
-	if req.Header.Get("Accept") == "text/csv" {
-		body, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return err
-		}
-		if p, ok := v.(*string); ok {
-			*p = string(body)
-		}
-	}
-
-	return nil
+	return res, nil
 }
 
 func (c *Client) getToken() (*sftoken, error) {
@@ -247,6 +333,8 @@ func (c *Client) getToken() (*sftoken, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.tokenMu.Lock()
 	c.token = &t
+	c.tokenMu.Unlock()
 	return &t, nil
 }
@@ -0,0 +1,114 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SearchResponse holds the records found by a SOSL search, across all the objects searched
+type SearchResponse struct {
+	SearchRecords []*Record `json:"searchRecords"`
+}
+
+// Types returns the object types of the records found, in the order they first appear
+func (sr *SearchResponse) Types() []string {
+	var types []string
+	seen := make(map[string]bool)
+	for _, r := range sr.SearchRecords {
+		if !seen[r.Attributes.Type] {
+			seen[r.Attributes.Type] = true
+			types = append(types, r.Attributes.Type)
+		}
+	}
+	return types
+}
+
+// Records returns the records found of the given object type
+func (sr *SearchResponse) Records(objectType string) []*Record {
+	var records []*Record
+	for _, r := range sr.SearchRecords {
+		if strings.EqualFold(r.Attributes.Type, objectType) {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// ParameterizedSearchRequest is a search using the parameterizedSearch endpoint, which takes the
+// search terms and options as fields rather than as a SOSL statement, so nothing needs escaping
+type ParameterizedSearchRequest struct {
+	Q            string         `json:"q"`
+	In           string         `json:"in,omitempty"` // ALL, NAME, EMAIL, PHONE or SIDEBAR
+	Fields       []string       `json:"fields,omitempty"`
+	SObjects     []SearchObject `json:"sobjects,omitempty"`
+	OverallLimit int            `json:"overallLimit,omitempty"`
+	DefaultLimit int            `json:"defaultLimit,omitempty"`
+}
+
+// SearchObject limits a parameterized search to an object, with the fields to return for it
+type SearchObject struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields,omitempty"`
+	Where  string   `json:"where,omitempty"`
+	Limit  int      `json:"limit,omitempty"`
+}
+
+// soslReserved are the characters that must be escaped in the search terms of a FIND clause,
+// apart from the * and ? wildcards
+const soslReserved = `&|!{}[]()^~:\"'+-`
+
+// EscapeSOSL escapes the reserved characters in search terms so they can be used in FIND {...}.
+// The * and ? wildcards are left as they are.
+func EscapeSOSL(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(soslReserved, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Search runs a SOSL search, e.g. FIND {Acme} IN NAME FIELDS RETURNING Account(Id, Name)
+func (c *Client) Search(ctx context.Context, sosl string) (*SearchResponse, error) {
+	if sosl == "" {
+		return nil, errors.New("search required")
+	}
+	sfurl := fmt.Sprintf("%s/services/data/%s/search?q=%s", c.BaseURL, c.Version, url.QueryEscape(sosl))
+	req, err := http.NewRequest("GET", sfurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	var sr SearchResponse
+	if err := c.makeRequest(ctx, req, &sr); err != nil {
+		return nil, err
+	}
+	return &sr, nil
+}
+
+// ParameterizedSearch runs a search without a SOSL statement
+func (c *Client) ParameterizedSearch(ctx context.Context, psr ParameterizedSearchRequest) (*SearchResponse, error) {
+	if psr.Q == "" {
+		return nil, errors.New("search terms required")
+	}
+	sfurl := fmt.Sprintf("%s/services/data/%s/parameterizedSearch", c.BaseURL, c.Version)
+	payload, err := json.Marshal(psr)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", sfurl, strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+	var sr SearchResponse
+	if err := c.makeRequest(ctx, req, &sr); err != nil {
+		return nil, err
+	}
+	return &sr, nil
+}
@@ -0,0 +1,144 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SaveResult is the response to creating or upserting a record
+type SaveResult struct {
+	ID      string      `json:"id"`
+	Success bool        `json:"success"`
+	Errors  []SaveError `json:"errors"`
+	// Created is set when an upsert created the record rather than updating it
+	Created bool `json:"created"`
+}
+
+// SaveError describes why a record couldn't be saved
+type SaveError struct {
+	StatusCode string   `json:"statusCode"`
+	Message    string   `json:"message"`
+	Fields     []string `json:"fields"`
+}
+
+func (e SaveError) Error() string {
+	if len(e.Fields) > 0 {
+		return fmt.Sprintf("%s: %s (%s)", e.StatusCode, e.Message, strings.Join(e.Fields, ", "))
+	}
+	return fmt.Sprintf("%s: %s", e.StatusCode, e.Message)
+}
+
+// err returns the errors in the result, if it wasn't successful
+func (r *SaveResult) err() error {
+	if r.Success || len(r.Errors) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		msgs[i] = e.Error()
+	}
+	return errors.New("salesforce: " + strings.Join(msgs, "; "))
+}
+
+func (s *SObjectService) url(object string, parts ...string) string {
+	sfurl := fmt.Sprintf("%s/services/data/%s/sobjects/%s", s.client.BaseURL, s.client.Version, object)
+	for _, p := range parts {
+		sfurl += "/" + url.PathEscape(p)
+	}
+	return sfurl
+}
+
+// Get returns a record by its id.  Only the given fields are returned, or all of them if none are given.
+func (s *SObjectService) Get(ctx context.Context, object, id string, fields ...string) (*Record, error) {
+	if object == "" || id == "" {
+		return nil, errors.New("object and id required")
+	}
+	return s.get(ctx, s.url(object, id), fields)
+}
+
+// GetByExternalID returns a record by the value of an external ID field
+func (s *SObjectService) GetByExternalID(ctx context.Context, object, field, value string, fields ...string) (*Record, error) {
+	if object == "" || field == "" || value == "" {
+		return nil, errors.New("object, external id field and value required")
+	}
+	return s.get(ctx, s.url(object, field, value), fields)
+}
+
+func (s *SObjectService) get(ctx context.Context, sfurl string, fields []string) (*Record, error) {
+	if len(fields) > 0 {
+		sfurl += "?fields=" + url.QueryEscape(strings.Join(fields, ","))
+	}
+	req, err := http.NewRequest("GET", sfurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	var r Record
+	if err := s.client.makeRequest(ctx, req, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Create creates a record from the fields, which can be a map of field names to values or a struct
+// that encodes to JSON with the field names
+func (s *SObjectService) Create(ctx context.Context, object string, fields interface{}) (*SaveResult, error) {
+	if object == "" {
+		return nil, errors.New("object required")
+	}
+	return s.save(ctx, "POST", s.url(object), fields)
+}
+
+// Update updates the fields of a record.  Fields set to nil are cleared.
+func (s *SObjectService) Update(ctx context.Context, object, id string, fields interface{}) error {
+	if object == "" || id == "" {
+		return errors.New("object and id required")
+	}
+	_, err := s.save(ctx, "PATCH", s.url(object, id), fields)
+	return err
+}
+
+// Upsert creates or updates the record with the given value in an external ID field.  Created is set
+// in the result when a record was created.  ErrMultipleExternalIDMatch is returned when the value
+// matches more than one record.
+func (s *SObjectService) Upsert(ctx context.Context, object, field, value string, fields interface{}) (*SaveResult, error) {
+	if object == "" || field == "" || value == "" {
+		return nil, errors.New("object, external id field and value required")
+	}
+	return s.save(ctx, "PATCH", s.url(object, field, value), fields)
+}
+
+// Delete deletes a record
+func (s *SObjectService) Delete(ctx context.Context, object, id string) error {
+	if object == "" || id == "" {
+		return errors.New("object and id required")
+	}
+	req, err := http.NewRequest("DELETE", s.url(object, id), nil)
+	if err != nil {
+		return err
+	}
+	return s.client.makeRequest(ctx, req, nil)
+}
+
+func (s *SObjectService) save(ctx context.Context, method, sfurl string, fields interface{}) (*SaveResult, error) {
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, sfurl, strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+	var res SaveResult
+	if err := s.client.makeRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	if err := res.err(); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
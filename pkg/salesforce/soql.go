@@ -0,0 +1,585 @@
+package salesforce
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SOQLBuilder builds a SOQL query, quoting and escaping the values in the WHERE and HAVING
+// clauses so they can safely come from user input, e.g.
+//
+//	soql, err := salesforce.Select("Id", "Name", "Owner.Name").
+//		Subquery(salesforce.Select("LastName").From("Contacts").Limit(5)).
+//		From("Account").
+//		Where(salesforce.And(
+//			salesforce.Eq("Industry", industry),
+//			salesforce.Ge("CreatedDate", salesforce.RelativeDate("LAST_N_DAYS", 30)),
+//		)).
+//		OrderByDesc("CreatedDate").
+//		Limit(100).
+//		Build()
+//
+// Errors, such as a value that can't be written as a literal, are returned by Build.  The object
+// and fields must be names, e.g. Owner.Name, or functions of them, e.g. COUNT(Id) cnt, so they
+// can't be used to add anything else to the query.
+type SOQLBuilder struct {
+	fields     []string
+	subqueries []*SOQLBuilder
+	object     string
+	where      Condition
+	groupBy    []string
+	having     Condition
+	orderBy    []string
+	limit      int
+	offset     int
+	forView    bool
+}
+
+// Select starts a query for the given fields, which can include parent relationship fields such
+// as Account.Name and aggregate functions such as COUNT(Id)
+func Select(fields ...string) *SOQLBuilder {
+	return &SOQLBuilder{fields: fields}
+}
+
+// Subquery adds a child relationship subquery, e.g. Select("LastName").From("Contacts")
+func (q *SOQLBuilder) Subquery(child *SOQLBuilder) *SOQLBuilder {
+	q.subqueries = append(q.subqueries, child)
+	return q
+}
+
+// From sets the object being queried, or for a subquery, the child relationship
+func (q *SOQLBuilder) From(object string) *SOQLBuilder {
+	q.object = object
+	return q
+}
+
+// Where sets the filter.  Combine conditions with And and Or.
+func (q *SOQLBuilder) Where(c Condition) *SOQLBuilder {
+	q.where = c
+	return q
+}
+
+// GroupBy sets the fields the results are grouped by
+func (q *SOQLBuilder) GroupBy(fields ...string) *SOQLBuilder {
+	q.groupBy = append(q.groupBy, fields...)
+	return q
+}
+
+// Having sets the filter applied to grouped results
+func (q *SOQLBuilder) Having(c Condition) *SOQLBuilder {
+	q.having = c
+	return q
+}
+
+// OrderBy adds fields to sort the results by in ascending order
+func (q *SOQLBuilder) OrderBy(fields ...string) *SOQLBuilder {
+	q.orderBy = append(q.orderBy, fields...)
+	return q
+}
+
+// OrderByDesc adds fields to sort the results by in descending order
+func (q *SOQLBuilder) OrderByDesc(fields ...string) *SOQLBuilder {
+	for _, f := range fields {
+		q.orderBy = append(q.orderBy, f+" DESC")
+	}
+	return q
+}
+
+// Limit sets the maximum number of records returned
+func (q *SOQLBuilder) Limit(n int) *SOQLBuilder {
+	q.limit = n
+	return q
+}
+
+// Offset sets the number of records skipped
+func (q *SOQLBuilder) Offset(n int) *SOQLBuilder {
+	q.offset = n
+	return q
+}
+
+// ForView updates the last viewed date of the records returned
+func (q *SOQLBuilder) ForView() *SOQLBuilder {
+	q.forView = true
+	return q
+}
+
+// String returns the query, or an empty string if it can't be built
+func (q *SOQLBuilder) String() string {
+	s, _ := q.Build()
+	return s
+}
+
+// Build returns the query
+func (q *SOQLBuilder) Build() (string, error) {
+	if q.object == "" {
+		return "", errors.New("soql: object required")
+	}
+	if len(q.fields) == 0 && len(q.subqueries) == 0 {
+		return "", errors.New("soql: at least one field required")
+	}
+	if q.limit < 0 || q.offset < 0 {
+		return "", errors.New("soql: limit and offset must not be negative")
+	}
+	if q.having != nil && len(q.groupBy) == 0 {
+		return "", errors.New("soql: having requires group by")
+	}
+	if err := q.checkNames(); err != nil {
+		return "", err
+	}
+
+	selected := append([]string{}, q.fields...)
+	for _, sub := range q.subqueries {
+		if sub.forView || len(sub.subqueries) > 0 {
+			return "", fmt.Errorf("soql: subquery on %s can't contain subqueries or FOR VIEW", sub.object)
+		}
+		s, err := sub.Build()
+		if err != nil {
+			return "", err
+		}
+		selected = append(selected, "("+s+")")
+	}
+
+	var b strings.Builder
+	b.WriteString("SELECT " + strings.Join(selected, ", ") + " FROM " + q.object)
+	if q.where != nil {
+		s, err := q.where.build()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(" WHERE " + s)
+	}
+	if len(q.groupBy) > 0 {
+		b.WriteString(" GROUP BY " + strings.Join(q.groupBy, ", "))
+	}
+	if q.having != nil {
+		s, err := q.having.build()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(" HAVING " + s)
+	}
+	if len(q.orderBy) > 0 {
+		b.WriteString(" ORDER BY " + strings.Join(q.orderBy, ", "))
+	}
+	if q.limit > 0 {
+		b.WriteString(" LIMIT " + strconv.Itoa(q.limit))
+	}
+	if q.offset > 0 {
+		b.WriteString(" OFFSET " + strconv.Itoa(q.offset))
+	}
+	if q.forView {
+		b.WriteString(" FOR VIEW")
+	}
+	return b.String(), nil
+}
+
+// Validate checks that the fields used by the query exist on the object described by dr.  The first
+// part of a relationship field such as Owner.Name must be a relationship of the object, but the rest
+// of the path and the fields of subqueries are on other objects and aren't checked.
+func (q *SOQLBuilder) Validate(dr *DescribeResponse) error {
+	if !strings.EqualFold(q.object, dr.Name) {
+		return fmt.Errorf("soql: query is on %s but the description is of %s", q.object, dr.Name)
+	}
+	fields := append([]string{}, q.fields...)
+	fields = append(fields, q.groupBy...)
+	for _, o := range q.orderBy {
+		f, err := orderField(o)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, f)
+	}
+	for _, c := range []Condition{q.where, q.having} {
+		if c != nil {
+			fields = append(fields, c.fields()...)
+		}
+	}
+	var unknown []string
+	for _, f := range fields {
+		name := fieldExpression(f)
+		if name == "" {
+			continue
+		}
+		if i := strings.Index(name, "."); i > 0 {
+			if relationshipIndex(dr, name[:i]) < 0 {
+				unknown = append(unknown, f)
+			}
+			continue
+		}
+		if fieldIndex(dr, name) < 0 {
+			unknown = append(unknown, f)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("soql: unknown fields on %s: %s", dr.Name, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+var (
+	functionPattern   = regexp.MustCompile(`^\w+\(\s*(.*?)\s*\)$`)
+	aliasPattern      = regexp.MustCompile(`^(.*\))\s+\w+$|^([\w.]+)\s+\w+$`)
+	objectPattern     = regexp.MustCompile(`^[A-Za-z]\w*$`)
+	identifierPattern = regexp.MustCompile(`^[A-Za-z]\w*(\.[A-Za-z]\w*)*$`)
+	orderPattern      = regexp.MustCompile(`(?i)^(\S+)(\s+(ASC|DESC))?(\s+NULLS\s+(FIRST|LAST))?$`)
+)
+
+// checkNames checks that the object and every field, including those in conditions, is a name or
+// function of a name, so nothing else can be added to the query through them
+func (q *SOQLBuilder) checkNames() error {
+	if !objectPattern.MatchString(q.object) {
+		return fmt.Errorf("soql: invalid object %q", q.object)
+	}
+	fields := append([]string{}, q.fields...)
+	fields = append(fields, q.groupBy...)
+	for _, c := range []Condition{q.where, q.having} {
+		if c != nil {
+			fields = append(fields, c.fields()...)
+		}
+	}
+	for _, f := range fields {
+		if !validExpression(f) {
+			return fmt.Errorf("soql: invalid field %q", f)
+		}
+	}
+	for _, o := range q.orderBy {
+		if _, err := orderField(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validExpression reports whether s is a field, such as Owner.Name, or a function of one with an
+// optional alias, such as CALENDAR_YEAR(CreatedDate) year or COUNT()
+func validExpression(s string) bool {
+	s = strings.TrimSpace(s)
+	if m := aliasPattern.FindStringSubmatch(s); m != nil {
+		s = m[1] + m[2]
+	}
+	for {
+		m := functionPattern.FindStringSubmatch(s)
+		if m == nil {
+			return identifierPattern.MatchString(s)
+		}
+		if m[1] == "" {
+			return true
+		}
+		s = m[1]
+	}
+}
+
+// orderField returns the field of an ORDER BY entry such as Name DESC NULLS LAST
+func orderField(entry string) (string, error) {
+	m := orderPattern.FindStringSubmatch(strings.TrimSpace(entry))
+	if m == nil || !validExpression(m[1]) {
+		return "", fmt.Errorf("soql: invalid order by %q", entry)
+	}
+	return m[1], nil
+}
+
+// fieldExpression returns the field used in a selected expression, e.g. CreatedDate from
+// CALENDAR_YEAR(convertTimezone(CreatedDate)) year, or an empty string if there isn't one, as with COUNT()
+func fieldExpression(s string) string {
+	s = strings.TrimSpace(s)
+	if m := aliasPattern.FindStringSubmatch(s); m != nil {
+		s = m[1] + m[2]
+	}
+	for {
+		m := functionPattern.FindStringSubmatch(s)
+		if m == nil {
+			return s
+		}
+		s = m[1]
+	}
+}
+
+// Condition is a filter in a WHERE or HAVING clause
+type Condition interface {
+	build() (string, error)
+	fields() []string
+}
+
+type comparison struct {
+	field string
+	op    string
+	value interface{}
+}
+
+func (c comparison) build() (string, error) {
+	lit, err := literal(c.value)
+	if err != nil {
+		return "", fmt.Errorf("soql: %s: %w", c.field, err)
+	}
+	if lit == "null" && c.op != "=" && c.op != "!=" {
+		return "", fmt.Errorf("soql: %s: null can only be compared with = or !=", c.field)
+	}
+	return c.field + " " + c.op + " " + lit, nil
+}
+
+func (c comparison) fields() []string {
+	return []string{c.field}
+}
+
+// Eq matches records where the field equals value.  A nil value matches null.
+func Eq(field string, value interface{}) Condition { return comparison{field, "=", value} }
+
+// Ne matches records where the field doesn't equal value.  A nil value matches fields that aren't null.
+func Ne(field string, value interface{}) Condition { return comparison{field, "!=", value} }
+
+// Lt matches records where the field is less than value
+func Lt(field string, value interface{}) Condition { return comparison{field, "<", value} }
+
+// Le matches records where the field is less than or equal to value
+func Le(field string, value interface{}) Condition { return comparison{field, "<=", value} }
+
+// Gt matches records where the field is greater than value
+func Gt(field string, value interface{}) Condition { return comparison{field, ">", value} }
+
+// Ge matches records where the field is greater than or equal to value
+func Ge(field string, value interface{}) Condition { return comparison{field, ">=", value} }
+
+// Like matches a pattern where % matches any characters and _ matches a single character
+func Like(field, pattern string) Condition {
+	return rawValue{field, "LIKE", "'" + escapeString(pattern, false) + "'"}
+}
+
+// Contains matches text fields containing s, with any % or _ in s matched literally
+func Contains(field, s string) Condition {
+	return rawValue{field, "LIKE", "'%" + escapeString(s, true) + "%'"}
+}
+
+// StartsWith matches text fields starting with s, with any % or _ in s matched literally
+func StartsWith(field, s string) Condition {
+	return rawValue{field, "LIKE", "'" + escapeString(s, true) + "%'"}
+}
+
+// IsNull matches records where the field is null
+func IsNull(field string) Condition { return comparison{field, "=", nil} }
+
+// NotNull matches records where the field isn't null
+func NotNull(field string) Condition { return comparison{field, "!=", nil} }
+
+type rawValue struct {
+	field string
+	op    string
+	value string
+}
+
+func (c rawValue) build() (string, error) {
+	return c.field + " " + c.op + " " + c.value, nil
+}
+
+func (c rawValue) fields() []string {
+	return []string{c.field}
+}
+
+type list struct {
+	field  string
+	op     string
+	values interface{}
+}
+
+func (c list) build() (string, error) {
+	v := reflect.ValueOf(c.values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("soql: %s %s requires a slice, not %T", c.field, c.op, c.values)
+	}
+	if v.Len() == 0 {
+		return "", fmt.Errorf("soql: %s %s requires at least one value", c.field, c.op)
+	}
+	lits := make([]string, v.Len())
+	for i := range lits {
+		lit, err := literal(v.Index(i).Interface())
+		if err != nil {
+			return "", fmt.Errorf("soql: %s: %w", c.field, err)
+		}
+		lits[i] = lit
+	}
+	return c.field + " " + c.op + " (" + strings.Join(lits, ", ") + ")", nil
+}
+
+func (c list) fields() []string {
+	return []string{c.field}
+}
+
+// In matches records where the field is one of values, which must be a slice, e.g. []string
+func In(field string, values interface{}) Condition { return list{field, "IN", values} }
+
+// NotIn matches records where the field isn't one of values, which must be a slice
+func NotIn(field string, values interface{}) Condition { return list{field, "NOT IN", values} }
+
+// Includes matches multi-select picklists that include any of values, which must be a slice.
+// A value can be several picklist values separated by semicolons to match records that include all of them.
+func Includes(field string, values interface{}) Condition { return list{field, "INCLUDES", values} }
+
+// Excludes matches multi-select picklists that exclude all of values, which must be a slice
+func Excludes(field string, values interface{}) Condition { return list{field, "EXCLUDES", values} }
+
+type logical struct {
+	op         string
+	conditions []Condition
+}
+
+func (c logical) build() (string, error) {
+	if len(c.conditions) == 0 {
+		return "", fmt.Errorf("soql: %s requires at least one condition", c.op)
+	}
+	parts := make([]string, len(c.conditions))
+	for i, cond := range c.conditions {
+		s, err := cond.build()
+		if err != nil {
+			return "", err
+		}
+		if _, ok := cond.(logical); ok && len(c.conditions) > 1 {
+			s = "(" + s + ")"
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, " "+c.op+" "), nil
+}
+
+func (c logical) fields() []string {
+	var fields []string
+	for _, cond := range c.conditions {
+		fields = append(fields, cond.fields()...)
+	}
+	return fields
+}
+
+// And matches records that match all of the conditions
+func And(conditions ...Condition) Condition { return logical{"AND", conditions} }
+
+// Or matches records that match any of the conditions
+func Or(conditions ...Condition) Condition { return logical{"OR", conditions} }
+
+type not struct {
+	condition Condition
+}
+
+func (c not) build() (string, error) {
+	s, err := c.condition.build()
+	if err != nil {
+		return "", err
+	}
+	return "NOT (" + s + ")", nil
+}
+
+func (c not) fields() []string {
+	return c.condition.fields()
+}
+
+// Not matches records that don't match the condition
+func Not(c Condition) Condition { return not{c} }
+
+// Literal is a value written into a query as it is, without quotes.  Use Date and DateTime to
+// create literals for dates.
+type Literal string
+
+// Date returns the date literal for t, e.g. 2006-01-02
+func Date(t time.Time) Literal {
+	return Literal(t.Format(dateLayout))
+}
+
+// DateTime returns the date time literal for t in UTC, e.g. 2006-01-02T15:04:05Z
+func DateTime(t time.Time) Literal {
+	return Literal(t.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+// DateLiteral is a relative date such as TODAY or LAST_N_DAYS:30
+type DateLiteral struct {
+	name string
+	n    []int
+}
+
+// RelativeDate returns a date literal such as TODAY or, for literals taking a number, LAST_N_DAYS:30
+// or N_DAYS_AGO:30.
+// An invalid literal is returned as an error by Build.
+func RelativeDate(name string, n ...int) DateLiteral {
+	return DateLiteral{name: strings.ToUpper(name), n: n}
+}
+
+var relativeDatePattern = regexp.MustCompile(`^[A-Z]+(_[A-Z0-9]+)*$`)
+
+func (d DateLiteral) literal() (string, error) {
+	takesN := strings.HasPrefix(d.name, "N_") || strings.Contains(d.name, "_N_")
+	switch {
+	case !relativeDatePattern.MatchString(d.name):
+		return "", fmt.Errorf("invalid date literal %q", d.name)
+	case takesN && len(d.n) != 1:
+		return "", fmt.Errorf("date literal %s requires a number", d.name)
+	case !takesN && len(d.n) != 0:
+		return "", fmt.Errorf("date literal %s doesn't take a number", d.name)
+	case takesN:
+		return d.name + ":" + strconv.Itoa(d.n[0]), nil
+	}
+	return d.name, nil
+}
+
+// literal returns the SOQL literal for a value
+func literal(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "null", nil
+	case Literal:
+		return string(v), nil
+	case DateLiteral:
+		return v.literal()
+	case string:
+		return "'" + escapeString(v, false) + "'", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return string(DateTime(v)), nil
+	case *time.Time:
+		if v == nil {
+			return "null", nil
+		}
+		return string(DateTime(*v)), nil
+	case fmt.Stringer:
+		return "'" + escapeString(v.String(), false) + "'", nil
+	}
+	return "", fmt.Errorf("can't use %T as a value", v)
+}
+
+// escapeString escapes the characters that must be escaped in a quoted SOQL string, and when
+// like is set, the LIKE wildcards as well
+func escapeString(s string, like bool) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '\'', '"':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '%', '_':
+			if like {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
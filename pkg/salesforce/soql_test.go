@@ -0,0 +1,213 @@
+package salesforce
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSOQLBuilderBuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *SOQLBuilder
+		want  string
+	}{
+		{
+			name:  "fields",
+			query: Select("Id", "Name", "Owner.Name").From("Account"),
+			want:  "SELECT Id, Name, Owner.Name FROM Account",
+		},
+		{
+			name:  "subquery",
+			query: Select("Id").Subquery(Select("LastName").From("Contacts").Limit(5)).From("Account"),
+			want:  "SELECT Id, (SELECT LastName FROM Contacts LIMIT 5) FROM Account",
+		},
+		{
+			name:  "escaped string",
+			query: Select("Id").From("Account").Where(Eq("Name", `O'Brien "Ltd" \ Co`)),
+			want:  `SELECT Id FROM Account WHERE Name = 'O\'Brien \"Ltd\" \\ Co'`,
+		},
+		{
+			name:  "control characters",
+			query: Select("Id").From("Account").Where(Eq("Description", "a\nb\tc\r")),
+			want:  `SELECT Id FROM Account WHERE Description = 'a\nb\tc\r'`,
+		},
+		{
+			name:  "null",
+			query: Select("Id").From("Contact").Where(And(IsNull("Email"), Ne("Phone", nil))),
+			want:  "SELECT Id FROM Contact WHERE Email = null AND Phone != null",
+		},
+		{
+			name:  "values",
+			query: Select("Id").From("Opportunity").Where(And(Gt("Amount", 1000.5), Le("Probability", 50), Eq("IsWon", true))),
+			want:  "SELECT Id FROM Opportunity WHERE Amount > 1000.5 AND Probability <= 50 AND IsWon = true",
+		},
+		{
+			name:  "dates",
+			query: Select("Id").From("Account").Where(Or(Ge("CreatedDate", time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)), Eq("LastActivityDate", Date(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC))))),
+			want:  "SELECT Id FROM Account WHERE CreatedDate >= 2021-03-04T05:06:07Z OR LastActivityDate = 2021-03-04",
+		},
+		{
+			name:  "relative dates",
+			query: Select("Id").From("Account").Where(And(Eq("CreatedDate", RelativeDate("today")), Ge("CreatedDate", RelativeDate("LAST_N_DAYS", 30)), Lt("CreatedDate", RelativeDate("N_DAYS_AGO", 5)))),
+			want:  "SELECT Id FROM Account WHERE CreatedDate = TODAY AND CreatedDate >= LAST_N_DAYS:30 AND CreatedDate < N_DAYS_AGO:5",
+		},
+		{
+			name:  "like",
+			query: Select("Id").From("Account").Where(Or(Like("Name", "Acme%"), Contains("Name", "50%_off"), StartsWith("Name", "it's"))),
+			want:  `SELECT Id FROM Account WHERE Name LIKE 'Acme%' OR Name LIKE '%50\%\_off%' OR Name LIKE 'it\'s%'`,
+		},
+		{
+			name:  "in",
+			query: Select("Id").From("Account").Where(And(In("Id", []string{"001A", "001'B"}), NotIn("Rating", []interface{}{"Hot", nil}))),
+			want:  `SELECT Id FROM Account WHERE Id IN ('001A', '001\'B') AND Rating NOT IN ('Hot', null)`,
+		},
+		{
+			name:  "includes",
+			query: Select("Id").From("Account").Where(Or(Includes("Region__c", []string{"EMEA;APAC", "AMER"}), Excludes("Region__c", [1]string{"LATAM"}))),
+			want:  "SELECT Id FROM Account WHERE Region__c INCLUDES ('EMEA;APAC', 'AMER') OR Region__c EXCLUDES ('LATAM')",
+		},
+		{
+			name:  "nested logic",
+			query: Select("Id").From("Account").Where(And(Eq("Type", "Customer"), Or(Eq("Rating", "Hot"), Not(Eq("Rating", "Cold"))))),
+			want:  "SELECT Id FROM Account WHERE Type = 'Customer' AND (Rating = 'Hot' OR NOT (Rating = 'Cold'))",
+		},
+		{
+			name: "aggregate",
+			query: Select("Industry", "COUNT(Id) total", "CALENDAR_YEAR(convertTimezone(CreatedDate)) yr").From("Account").
+				GroupBy("Industry").Having(Gt("COUNT(Id)", 1)).OrderByDesc("Industry").OrderBy("yr ASC NULLS LAST"),
+			want: "SELECT Industry, COUNT(Id) total, CALENDAR_YEAR(convertTimezone(CreatedDate)) yr FROM Account GROUP BY Industry HAVING COUNT(Id) > 1 ORDER BY Industry DESC, yr ASC NULLS LAST",
+		},
+		{
+			name:  "limit offset for view",
+			query: Select("COUNT()").From("Account").Limit(10).Offset(20).ForView(),
+			want:  "SELECT COUNT() FROM Account LIMIT 10 OFFSET 20 FOR VIEW",
+		},
+	}
+	for _, tt := range tests {
+		got, err := tt.query.Build()
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSOQLBuilderBuildErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *SOQLBuilder
+		want  string
+	}{
+		{"no object", Select("Id"), "object required"},
+		{"no fields", Select().From("Account"), "at least one field"},
+		{"negative limit", Select("Id").From("Account").Limit(-1), "must not be negative"},
+		{"having without group by", Select("COUNT(Id)").From("Account").Having(Gt("COUNT(Id)", 1)), "having requires group by"},
+		{"empty order by", Select("Id").From("Account").OrderBy(""), "invalid order by"},
+		{"blank order by", Select("Id").From("Account").OrderBy("  "), "invalid order by"},
+		{"order by injection", Select("Id").From("Account").OrderBy("Name; DELETE"), "invalid order by"},
+		{"object injection", Select("Id").From("Account WHERE Id != null --"), "invalid object"},
+		{"field injection", Select("Id FROM User --").From("Account"), "invalid field"},
+		{"condition field injection", Select("Id").From("Account").Where(Eq("Name = 'x' OR Id", "y")), "invalid field"},
+		{"group by injection", Select("Name").From("Account").GroupBy("Name)"), "invalid field"},
+		{"less than null", Select("Id").From("Opportunity").Where(Lt("Amount", nil)), "null can only be compared"},
+		{"greater than nil time", Select("Id").From("Account").Where(Ge("CreatedDate", (*time.Time)(nil))), "null can only be compared"},
+		{"in not a slice", Select("Id").From("Account").Where(In("Id", "001A")), "requires a slice"},
+		{"in empty", Select("Id").From("Account").Where(In("Id", []string{})), "at least one value"},
+		{"unsupported value", Select("Id").From("Account").Where(Eq("Name", struct{}{})), "can't use"},
+		{"empty and", Select("Id").From("Account").Where(And()), "at least one condition"},
+		{"date literal without number", Select("Id").From("Account").Where(Eq("CreatedDate", RelativeDate("N_DAYS_AGO"))), "requires a number"},
+		{"date literal with number", Select("Id").From("Account").Where(Eq("CreatedDate", RelativeDate("TODAY", 1))), "doesn't take a number"},
+		{"invalid date literal", Select("Id").From("Account").Where(Eq("CreatedDate", RelativeDate("TODAY OR Id != null"))), "invalid date literal"},
+		{"nested subquery", Select("Id").Subquery(Select("Id").Subquery(Select("Id").From("Cases")).From("Contacts")).From("Account"), "can't contain subqueries"},
+	}
+	for _, tt := range tests {
+		got, err := tt.query.Build()
+		if err == nil {
+			t.Errorf("%s: expected an error, got %s", tt.name, got)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSOQLBuilderValidate(t *testing.T) {
+	dr := &DescribeResponse{
+		Name: "Contact",
+		Fields: []Field{
+			{Name: "Id"},
+			{Name: "LastName"},
+			{Name: "CreatedDate"},
+			{Name: "AccountId", RelationshipName: "Account"},
+		},
+	}
+	tests := []struct {
+		name  string
+		query *SOQLBuilder
+		want  string // empty if the query is valid
+	}{
+		{"valid", Select("Id", "lastname", "Account.Name", "COUNT()").From("Contact").Where(Eq("Account.Owner.Email", "x")).OrderByDesc("CreatedDate"), ""},
+		{"function and alias", Select("CALENDAR_YEAR(CreatedDate) yr").From("Contact").GroupBy("CALENDAR_YEAR(CreatedDate)"), ""},
+		{"wrong object", Select("Id").From("Account"), "description is of Contact"},
+		{"unknown fields", Select("Id", "FirstName", "Owner.Name").From("Contact").OrderBy("Email NULLS FIRST"), "unknown fields on Contact: FirstName, Owner.Name, Email"},
+		{"unknown condition field", Select("Id").From("Contact").Where(Eq("Title", "CEO")), "unknown fields on Contact: Title"},
+		{"empty order by", Select("Id").From("Contact").OrderBy(""), "invalid order by"},
+	}
+	for _, tt := range tests {
+		err := tt.query.Validate(dr)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s: %s", tt.name, err)
+		case tt.want != "" && err == nil:
+			t.Errorf("%s: expected an error", tt.name)
+		case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+			t.Errorf("%s: got error %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestFieldExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"Name", "Name"},
+		{" Owner.Name ", "Owner.Name"},
+		{"Owner.Name ownerName", "Owner.Name"},
+		{"COUNT(Id)", "Id"},
+		{"COUNT(Id) total", "Id"},
+		{"COUNT()", ""},
+		{"CALENDAR_YEAR(convertTimezone(CreatedDate)) yr", "CreatedDate"},
+		{"toLabel( Status )", "Status"},
+	}
+	for _, tt := range tests {
+		if got := fieldExpression(tt.expr); got != tt.want {
+			t.Errorf("fieldExpression(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeString(t *testing.T) {
+	tests := []struct {
+		s    string
+		like bool
+		want string
+	}{
+		{"plain", false, "plain"},
+		{`it's "quoted" \ here`, false, `it\'s \"quoted\" \\ here`},
+		{"tab\tnew\nline\rfeed\fback\b", false, `tab\tnew\nline\rfeed\fback\b`},
+		{"50%_off", false, "50%_off"},
+		{"50%_off", true, `50\%\_off`},
+		{"ünïcödé", true, "ünïcödé"},
+	}
+	for _, tt := range tests {
+		if got := escapeString(tt.s, tt.like); got != tt.want {
+			t.Errorf("escapeString(%q, %v) = %q, want %q", tt.s, tt.like, got, tt.want)
+		}
+	}
+}
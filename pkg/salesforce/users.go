@@ -0,0 +1,57 @@
+package salesforce
+
+import (
+	"context"
+	"time"
+)
+
+// User is a subset of the standard fields of a User, which can be deactivated but not deleted
+type User struct {
+	ID                string     `sf:"Id" json:"-"`
+	Username          string     `json:"Username,omitempty"`
+	FirstName         string     `json:"FirstName,omitempty"`
+	LastName          string     `json:"LastName,omitempty"`
+	Name              string     `sf:"Name" json:"-"`
+	Alias             string     `json:"Alias,omitempty"`
+	Email             string     `json:"Email,omitempty"`
+	Title             string     `json:"Title,omitempty"`
+	Department        string     `json:"Department,omitempty"`
+	IsActive          bool       `json:"IsActive,omitempty"`
+	ProfileID         string     `sf:"ProfileId" json:"ProfileId,omitempty"`
+	UserRoleID        string     `sf:"UserRoleId" json:"UserRoleId,omitempty"`
+	ManagerID         string     `sf:"ManagerId" json:"ManagerId,omitempty"`
+	TimeZoneSidKey    string     `json:"TimeZoneSidKey,omitempty"`
+	LocaleSidKey      string     `json:"LocaleSidKey,omitempty"`
+	EmailEncodingKey  string     `json:"EmailEncodingKey,omitempty"`
+	LanguageLocaleKey string     `json:"LanguageLocaleKey,omitempty"`
+	LastLoginDate     *time.Time `sf:"LastLoginDate" json:"-"`
+	CreatedDate       *time.Time `sf:"CreatedDate" json:"-"`
+	LastModifiedDate  *time.Time `sf:"LastModifiedDate" json:"-"`
+}
+
+// Get returns the user with the given id
+func (s *UserService) Get(ctx context.Context, id string) (*User, error) {
+	r, err := s.client.SObjectService.Get(ctx, "User", id)
+	if err != nil {
+		return nil, err
+	}
+	var u User
+	if err := r.Decode(&u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Create creates a user, returning its id
+func (s *UserService) Create(ctx context.Context, u *User) (string, error) {
+	res, err := s.client.SObjectService.Create(ctx, "User", u)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// Update updates the fields of the user with the given id that are set in u
+func (s *UserService) Update(ctx context.Context, id string, u *User) error {
+	return s.client.SObjectService.Update(ctx, "User", id, u)
+}
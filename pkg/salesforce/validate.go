@@ -0,0 +1,405 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError describes a problem with a CSV header or value that would cause
+// the record to fail if it were loaded with the bulk API.
+type ValidationError struct {
+	Row     int    // record number starting at 1, or 0 for the header
+	Column  string // CSV column name
+	Value   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Row == 0 {
+		return fmt.Sprintf("header %s: %s", e.Column, e.Message)
+	}
+	return fmt.Sprintf("row %d %s %q: %s", e.Row, e.Column, e.Value, e.Message)
+}
+
+// Validator checks CSV data against the describe metadata for an object so that problems
+// can be reported before a bulk ingest job is created.  Use Client.NewValidator to create one.
+type Validator struct {
+	client     *Client
+	object     *DescribeResponse
+	operation  string
+	externalID string
+	related    map[string]*DescribeResponse
+	columns    []validatorColumn
+}
+
+// validatorColumn links a CSV column to the describe metadata of the field it will be loaded into
+type validatorColumn struct {
+	name     string
+	describe *DescribeResponse // object the field belongs to, which for relationship columns is the related object
+	field    int               // index into describe.Fields, or -1 if the column can't be validated
+	lookup   bool              // column is a relationship column, e.g. Owner.Email
+}
+
+var idPattern = regexp.MustCompile(`^[a-zA-Z0-9]{15}([a-zA-Z0-9]{3})?$`)
+
+// NewValidator returns a Validator for loading the given object with the given operation,
+// e.g. insert, update or upsert.  The external ID field name is only required for upserts.
+func (c *Client) NewValidator(ctx context.Context, object, operation, externalID string) (*Validator, error) {
+	dr, err := c.Describe(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{
+		client:     c,
+		object:     dr,
+		operation:  strings.ToLower(operation),
+		externalID: externalID,
+		related:    map[string]*DescribeResponse{strings.ToLower(dr.Name): dr},
+	}, nil
+}
+
+// ValidateCSV validates the header and every record in r, returning the problems found and the number of records read.
+// An error is only returned if the CSV itself can't be read.
+func (v *Validator) ValidateCSV(ctx context.Context, r io.Reader, delimiter rune) ([]ValidationError, int, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = delimiter
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return []ValidationError{{Message: "file is empty"}}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	problems, err := v.ValidateHeader(ctx, header)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, rows, err
+		}
+		rows++
+		problems = append(problems, v.ValidateRecord(rows, record)...)
+	}
+	return problems, rows, nil
+}
+
+// ValidateHeader checks that every column refers to a field that can be written by the operation,
+// that relationship columns refer to a unique field on the related object and that the
+// external ID field is suitable.  It must be called before ValidateRecord.
+func (v *Validator) ValidateHeader(ctx context.Context, header []string) ([]ValidationError, error) {
+	var problems []ValidationError
+	add := func(column, format string, a ...interface{}) {
+		problems = append(problems, ValidationError{Column: column, Message: fmt.Sprintf(format, a...)})
+	}
+
+	v.columns = make([]validatorColumn, len(header))
+	seen := make(map[string]bool)
+	present := make(map[string]bool) // field names of the main object covered by the header
+	for i, name := range header {
+		v.columns[i] = validatorColumn{name: name, describe: v.object, field: -1}
+		if name == "" {
+			add(name, "empty column name")
+			continue
+		}
+		if seen[strings.ToLower(name)] {
+			add(name, "duplicate column")
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		if strings.Contains(name, ".") {
+			if err := v.relationshipColumn(ctx, i, add); err != nil {
+				return nil, err
+			}
+			if f := v.columns[i].field; f >= 0 {
+				present[strings.ToLower(v.referenceField(name))] = true
+			}
+			continue
+		}
+
+		idx := fieldIndex(v.object, name)
+		if idx < 0 {
+			add(name, "no such field on %s", v.object.Name)
+			continue
+		}
+		v.columns[i].field = idx
+		present[strings.ToLower(v.object.Fields[idx].Name)] = true
+		f := v.object.Fields[idx]
+		isID := strings.EqualFold(f.Name, "Id")
+		switch v.operation {
+		case "insert":
+			if !f.Createable {
+				add(name, "field is not createable")
+			}
+		case "update":
+			if !f.Updateable && !isID {
+				add(name, "field is not updateable")
+			}
+		case "upsert":
+			if !f.Createable && !f.Updateable && !isID {
+				add(name, "field is neither createable nor updateable")
+			}
+		case "delete", "harddelete":
+			if !isID {
+				add(name, "only the Id column can be provided for a %s", v.operation)
+			}
+		}
+	}
+
+	switch v.operation {
+	case "insert":
+		for _, f := range v.object.Fields {
+			if f.RequiredOnCreate() && !present[strings.ToLower(f.Name)] {
+				add(f.Name, "required field is missing")
+			}
+		}
+	case "update", "delete", "harddelete":
+		if !present["id"] {
+			add("Id", "Id column is required for %s", v.operation)
+		}
+	case "upsert":
+		if v.externalID == "" {
+			add("", "external ID field is required for upsert")
+			break
+		}
+		idx := fieldIndex(v.object, v.externalID)
+		switch {
+		case idx < 0:
+			add(v.externalID, "external ID field does not exist on %s", v.object.Name)
+		case !v.object.Fields[idx].ExternalID && !v.object.Fields[idx].IDLookup:
+			add(v.externalID, "field is not an external ID or ID lookup field")
+		case !present[strings.ToLower(v.externalID)]:
+			add(v.externalID, "external ID column is missing")
+		}
+	}
+	return problems, nil
+}
+
+// relationshipColumn validates a column such as Owner.Email or, for polymorphic fields, User:Owner.Email
+func (v *Validator) relationshipColumn(ctx context.Context, i int, add func(column, format string, a ...interface{})) error {
+	name := v.columns[i].name
+	objectType, relationship, field, ok := RelationshipColumn(name)
+	if !ok {
+		add(name, "invalid relationship column")
+		return nil
+	}
+	idx := relationshipIndex(v.object, relationship)
+	if idx < 0 {
+		add(name, "no relationship named %s on %s", relationship, v.object.Name)
+		return nil
+	}
+	ref := v.object.Fields[idx]
+	switch v.operation {
+	case "insert":
+		if !ref.Createable {
+			add(name, "relationship field %s is not createable", ref.Name)
+		}
+	case "update":
+		if !ref.Updateable {
+			add(name, "relationship field %s is not updateable", ref.Name)
+		}
+	case "upsert":
+		if !ref.Createable && !ref.Updateable {
+			add(name, "relationship field %s is neither createable nor updateable", ref.Name)
+		}
+	}
+
+	target := ""
+	switch {
+	case objectType != "":
+		for _, t := range ref.ReferenceTo {
+			if strings.EqualFold(t, objectType) {
+				target = t
+			}
+		}
+		if target == "" {
+			add(name, "%s does not refer to %s", ref.Name, objectType)
+			return nil
+		}
+	case len(ref.ReferenceTo) == 1:
+		target = ref.ReferenceTo[0]
+	default:
+		add(name, "%s is polymorphic, use ObjectType:%s.%s", ref.Name, relationship, field)
+		return nil
+	}
+
+	dr, ok := v.related[strings.ToLower(target)]
+	if !ok {
+		var err error
+		if dr, err = v.client.Describe(ctx, target); err != nil {
+			return fmt.Errorf("describing %s: %w", target, err)
+		}
+		v.related[strings.ToLower(target)] = dr
+	}
+	tf := fieldIndex(dr, field)
+	if tf < 0 {
+		add(name, "no such field %s on %s", field, target)
+		return nil
+	}
+	if !dr.Fields[tf].IDLookup && !dr.Fields[tf].ExternalID {
+		add(name, "%s.%s is not an external ID or ID lookup field", target, dr.Fields[tf].Name)
+		return nil
+	}
+	v.columns[i].describe = dr
+	v.columns[i].field = tf
+	v.columns[i].lookup = true
+	return nil
+}
+
+// referenceField returns the name of the reference field for a relationship column
+func (v *Validator) referenceField(column string) string {
+	_, relationship, _, _ := RelationshipColumn(column)
+	if idx := relationshipIndex(v.object, relationship); idx >= 0 {
+		return v.object.Fields[idx].Name
+	}
+	return ""
+}
+
+// ValidateRecord checks each value in the record against the type, length and picklist
+// values of the field it will be loaded into.  Row is used to identify the record in any problems.
+func (v *Validator) ValidateRecord(row int, record []string) []ValidationError {
+	var problems []ValidationError
+	if len(record) != len(v.columns) {
+		return append(problems, ValidationError{Row: row, Message: fmt.Sprintf("expected %d values, found %d", len(v.columns), len(record))})
+	}
+	for i, value := range record {
+		c := v.columns[i]
+		if c.field < 0 {
+			continue
+		}
+		if msg := v.checkValue(c, value); msg != "" {
+			problems = append(problems, ValidationError{Row: row, Column: c.name, Value: value, Message: msg})
+		}
+	}
+	return problems
+}
+
+// checkValue returns a description of the problem with the value, or an empty string if there isn't one
+func (v *Validator) checkValue(c validatorColumn, value string) string {
+	f := c.describe.Fields[c.field]
+	if value == "" || value == "#N/A" {
+		required := !f.Nillable && f.Type != "boolean" && !c.lookup
+		switch {
+		case value == "#N/A" && required:
+			return "field cannot be set to null"
+		case value == "" && required && v.operation == "insert" && !f.DefaultedOnCreate:
+			return "required value is missing"
+		}
+		return ""
+	}
+	if c.lookup {
+		if f.Length > 0 && utf8.RuneCountInString(value) > f.Length {
+			return fmt.Sprintf("value exceeds the maximum length of %d", f.Length)
+		}
+		return ""
+	}
+
+	switch f.Type {
+	case "boolean":
+		switch strings.ToLower(value) {
+		case "true", "false", "1", "0":
+		default:
+			return "value is not a boolean"
+		}
+	case "int":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "value is not an integer"
+		}
+		if f.Digits > 0 && len(strings.TrimLeft(value, "+-")) > f.Digits {
+			return fmt.Sprintf("value exceeds %d digits", f.Digits)
+		}
+	case "double", "currency", "percent":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "value is not a number"
+		}
+		if f.Precision > 0 {
+			whole := strings.TrimLeft(strings.SplitN(value, ".", 2)[0], "+-")
+			if len(strings.TrimLeft(whole, "0")) > f.Precision-f.Scale {
+				return fmt.Sprintf("value exceeds %d digits before the decimal point", f.Precision-f.Scale)
+			}
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "value is not a date in the format YYYY-MM-DD"
+		}
+	case "datetime":
+		if !isDateTime(value) {
+			return "value is not an ISO 8601 date time, e.g. 2006-01-02T15:04:05Z"
+		}
+	case "time":
+		if !isTime(value) {
+			return "value is not a time, e.g. 15:04:05.000Z"
+		}
+	case "id", "reference":
+		if !idPattern.MatchString(value) {
+			return "value is not a valid salesforce id"
+		}
+	case "email":
+		if at := strings.Index(value, "@"); at < 1 || at == len(value)-1 || strings.Count(value, "@") != 1 {
+			return "value is not an email address"
+		}
+	}
+
+	if f.Length > 0 && utf8.RuneCountInString(value) > f.Length {
+		return fmt.Sprintf("value exceeds the maximum length of %d", f.Length)
+	}
+
+	if f.RestrictedPicklist && (f.Type == "picklist" || f.Type == "multipicklist") {
+		values := []string{value}
+		if f.Type == "multipicklist" {
+			values = strings.Split(value, ";")
+		}
+	values:
+		for _, val := range values {
+			for _, p := range f.PicklistValues {
+				if p.Active && p.Value == val {
+					continue values
+				}
+			}
+			return fmt.Sprintf("%q is not a value of the restricted picklist", val)
+		}
+	}
+	return ""
+}
+
+var dateTimeLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05.000Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05.000Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.000",
+}
+
+func isDateTime(value string) bool {
+	for _, layout := range dateTimeLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isTime(value string) bool {
+	for _, layout := range []string{"15:04:05Z07:00", "15:04:05.000Z07:00", "15:04:05", "15:04:05.000"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
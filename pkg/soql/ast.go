@@ -0,0 +1,277 @@
+package soql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed SOQL statement, or a subquery within one
+type Query struct {
+	Fields  []*Field
+	Object  string
+	Alias   string
+	Scope   string // USING SCOPE
+	Where   Expr
+	With    string // e.g. SECURITY_ENFORCED
+	GroupBy []*Field
+	Having  Expr
+	OrderBy []*Order
+	Limit   *int
+	Offset  *int
+	For     string // VIEW, REFERENCE or UPDATE
+	Pos     Pos
+}
+
+// Field is an item in the SELECT list, GROUP BY or a condition.  It is either a field path such
+// as Account.Name, a function such as COUNT(Id) or FIELDS(ALL), or a child relationship subquery.
+type Field struct {
+	Name     string // field path, or the function name when Function is set
+	Function bool
+	Args     []*Field
+	Alias    string
+	Subquery *Query
+	Pos      Pos
+}
+
+// IsAggregate reports whether the field is an aggregate function such as COUNT or SUM
+func (f *Field) IsAggregate() bool {
+	if !f.Function {
+		return false
+	}
+	switch strings.ToUpper(f.Name) {
+	case "AVG", "COUNT", "COUNT_DISTINCT", "MIN", "MAX", "SUM":
+		return true
+	}
+	return false
+}
+
+// Paths returns the field paths used by the field, including the arguments of functions
+// but not the fields of subqueries
+func (f *Field) Paths() []*Field {
+	if f.Subquery != nil {
+		return nil
+	}
+	if !f.Function {
+		return []*Field{f}
+	}
+	var paths []*Field
+	for _, a := range f.Args {
+		paths = append(paths, a.Paths()...)
+	}
+	return paths
+}
+
+// Order is an item in the ORDER BY clause
+type Order struct {
+	Field *Field
+	Desc  bool
+	Nulls string // FIRST or LAST, or empty for the default
+}
+
+// Expr is a condition in a WHERE or HAVING clause, either a *Comparison, *Logical or *Not
+type Expr interface {
+	String() string
+}
+
+// Comparison compares a field with a value, a list of values or a semi-join subquery
+type Comparison struct {
+	Field    *Field
+	Op       string // =, !=, <, <=, >, >=, LIKE, IN, NOT IN, INCLUDES or EXCLUDES
+	Value    *Value
+	Values   []*Value
+	Subquery *Query
+}
+
+// Logical joins conditions with AND or OR
+type Logical struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// Not negates a condition
+type Not struct {
+	Expr Expr
+}
+
+// ValueKind is the type of a literal value
+type ValueKind int
+
+// Kinds of value
+const (
+	String ValueKind = iota
+	Number
+	Boolean
+	Null
+	Date
+	DateTime
+	DateLiteral // e.g. TODAY or LAST_N_DAYS:30
+	Currency    // e.g. USD5000
+	Bind        // Apex bind variable, e.g. :name
+)
+
+// Value is a literal value in a condition.  Text is unescaped for strings, apart from escaped
+// LIKE wildcards such as \%.
+type Value struct {
+	Kind ValueKind
+	Text string
+	Pos  Pos
+}
+
+func (q *Query) String() string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	for i, f := range q.Fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(f.String())
+	}
+	b.WriteString(" FROM " + q.Object)
+	if q.Alias != "" {
+		b.WriteString(" " + q.Alias)
+	}
+	if q.Scope != "" {
+		b.WriteString(" USING SCOPE " + q.Scope)
+	}
+	if q.Where != nil {
+		b.WriteString(" WHERE " + q.Where.String())
+	}
+	if q.With != "" {
+		b.WriteString(" WITH " + q.With)
+	}
+	if len(q.GroupBy) > 0 {
+		b.WriteString(" GROUP BY ")
+		for i, f := range q.GroupBy {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(f.String())
+		}
+	}
+	if q.Having != nil {
+		b.WriteString(" HAVING " + q.Having.String())
+	}
+	if len(q.OrderBy) > 0 {
+		b.WriteString(" ORDER BY ")
+		for i, o := range q.OrderBy {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(o.Field.String())
+			if o.Desc {
+				b.WriteString(" DESC")
+			}
+			if o.Nulls != "" {
+				b.WriteString(" NULLS " + o.Nulls)
+			}
+		}
+	}
+	if q.Limit != nil {
+		b.WriteString(" LIMIT " + strconv.Itoa(*q.Limit))
+	}
+	if q.Offset != nil {
+		b.WriteString(" OFFSET " + strconv.Itoa(*q.Offset))
+	}
+	if q.For != "" {
+		b.WriteString(" FOR " + q.For)
+	}
+	return b.String()
+}
+
+func (f *Field) String() string {
+	var s string
+	switch {
+	case f.Subquery != nil:
+		s = "(" + f.Subquery.String() + ")"
+	case f.Function:
+		args := make([]string, len(f.Args))
+		for i, a := range f.Args {
+			args[i] = a.String()
+		}
+		s = f.Name + "(" + strings.Join(args, ", ") + ")"
+	default:
+		s = f.Name
+	}
+	if f.Alias != "" {
+		s += " " + f.Alias
+	}
+	return s
+}
+
+func (c *Comparison) String() string {
+	s := c.Field.String() + " " + c.Op + " "
+	switch {
+	case c.Subquery != nil:
+		return s + "(" + c.Subquery.String() + ")"
+	case c.Value != nil:
+		return s + c.Value.String()
+	}
+	values := make([]string, len(c.Values))
+	for i, v := range c.Values {
+		values[i] = v.String()
+	}
+	return s + "(" + strings.Join(values, ", ") + ")"
+}
+
+func (l *Logical) String() string {
+	return l.operand(l.Left) + " " + l.Op + " " + l.operand(l.Right)
+}
+
+// operand wraps a nested condition in parentheses when it joins conditions with a different operator
+func (l *Logical) operand(e Expr) string {
+	if inner, ok := e.(*Logical); ok && inner.Op != l.Op {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+func (n *Not) String() string {
+	return "NOT (" + n.Expr.String() + ")"
+}
+
+func (v *Value) String() string {
+	switch v.Kind {
+	case String:
+		return "'" + escape(v.Text) + "'"
+	case Bind:
+		return ":" + v.Text
+	}
+	return v.Text
+}
+
+// escape escapes a string value, leaving escaped LIKE wildcards as they are
+func escape(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == '%' || runes[i+1] == '_') {
+				b.WriteRune(r)
+				b.WriteRune(runes[i+1])
+				i++
+				continue
+			}
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package soql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+)
+
+// bulkUnsupportedTypes are the field types the Bulk API can't return, which are left out when
+// FIELDS() is expanded
+var bulkUnsupportedTypes = map[string]bool{
+	"address":  true,
+	"location": true,
+	"base64":   true,
+}
+
+// UsesFields reports whether the query selects FIELDS(ALL), FIELDS(STANDARD) or FIELDS(CUSTOM)
+func (q *Query) UsesFields() bool {
+	for _, f := range q.Fields {
+		if f.Function && strings.EqualFold(f.Name, "FIELDS") {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandFields replaces FIELDS(ALL), FIELDS(STANDARD) and FIELDS(CUSTOM) in the select list with the
+// fields of the object described by dr, which the Bulk API requires and which lifts the limit of 200
+// records the REST API puts on FIELDS(ALL).  Compound and base64 fields are left out since the Bulk API
+// can't return them, as are fields that are already selected.
+func (q *Query) ExpandFields(dr *salesforce.DescribeResponse) error {
+	if !strings.EqualFold(q.Object, dr.Name) {
+		return fmt.Errorf("soql: query is on %s but the description is of %s", q.Object, dr.Name)
+	}
+	for _, f := range q.Fields {
+		if f.Subquery != nil && f.Subquery.UsesFields() {
+			return fmt.Errorf("soql: %s: FIELDS() can't be expanded in a subquery", f.Pos)
+		}
+	}
+	selected := make(map[string]bool)
+	for _, f := range q.Fields {
+		if !f.Function && f.Subquery == nil {
+			selected[strings.ToLower(f.Name)] = true
+		}
+	}
+	var fields []*Field
+	for _, f := range q.Fields {
+		if !f.Function || !strings.EqualFold(f.Name, "FIELDS") {
+			fields = append(fields, f)
+			continue
+		}
+		if len(f.Args) != 1 {
+			return fmt.Errorf("soql: %s: FIELDS requires ALL, STANDARD or CUSTOM", f.Pos)
+		}
+		kind := strings.ToUpper(f.Args[0].Name)
+		if kind != "ALL" && kind != "STANDARD" && kind != "CUSTOM" {
+			return fmt.Errorf("soql: %s: FIELDS requires ALL, STANDARD or CUSTOM, not %s", f.Pos, f.Args[0].Name)
+		}
+		for _, df := range dr.Fields {
+			if bulkUnsupportedTypes[df.Type] || selected[strings.ToLower(df.Name)] {
+				continue
+			}
+			if (kind == "STANDARD" && df.Custom) || (kind == "CUSTOM" && !df.Custom) {
+				continue
+			}
+			selected[strings.ToLower(df.Name)] = true
+			fields = append(fields, &Field{Name: df.Name, Pos: f.Pos})
+		}
+	}
+	q.Fields = fields
+	return nil
+}
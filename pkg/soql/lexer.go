@@ -0,0 +1,264 @@
+package soql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Pos is a position in the statement, counting lines and columns from 1
+type Pos struct {
+	Line   int
+	Column int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("line %d column %d", p.Line, p.Column)
+}
+
+// Error is a syntax error in a statement
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("soql: %s: %s", e.Pos, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDate
+	tokenDateTime
+	tokenBind
+	tokenOperator
+	tokenComma
+	tokenLParen
+	tokenRParen
+	tokenColon
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  Pos
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of statement"
+	case tokenString:
+		return "'" + t.text + "'"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// is reports whether the token is the keyword, ignoring case
+func (t token) is(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+type lexer struct {
+	src  []rune
+	i    int
+	line int
+	col  int
+}
+
+// lex splits the statement into tokens.  Strings are unescaped, and dates and date times
+// are recognised so that they aren't mistaken for arithmetic.
+func lex(s string) ([]token, error) {
+	l := &lexer{src: []rune(s), line: 1, col: 1}
+	var tokens []token
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		if t.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) peek(n int) rune {
+	if l.i+n >= len(l.src) {
+		return 0
+	}
+	return l.src[l.i+n]
+}
+
+func (l *lexer) advance() rune {
+	r := l.src[l.i]
+	l.i++
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+func (l *lexer) next() (token, error) {
+	for l.i < len(l.src) && unicode.IsSpace(l.src[l.i]) {
+		l.advance()
+	}
+	pos := Pos{l.line, l.col}
+	if l.i >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+	r := l.peek(0)
+	switch {
+	case r == '\'':
+		return l.string(pos)
+	case r == ',':
+		l.advance()
+		return token{tokenComma, ",", pos}, nil
+	case r == '(':
+		l.advance()
+		return token{tokenLParen, "(", pos}, nil
+	case r == ')':
+		l.advance()
+		return token{tokenRParen, ")", pos}, nil
+	case r == ':':
+		l.advance()
+		if isIdentStart(l.peek(0)) {
+			t := l.ident(pos)
+			return token{tokenBind, t.text, pos}, nil
+		}
+		return token{tokenColon, ":", pos}, nil
+	case r == '=':
+		l.advance()
+		return token{tokenOperator, "=", pos}, nil
+	case r == '!' && l.peek(1) == '=':
+		l.advance()
+		l.advance()
+		return token{tokenOperator, "!=", pos}, nil
+	case r == '<' || r == '>':
+		l.advance()
+		op := string(r)
+		if l.peek(0) == '=' || (r == '<' && l.peek(0) == '>') {
+			op += string(l.advance())
+		}
+		return token{tokenOperator, op, pos}, nil
+	case unicode.IsDigit(r) || ((r == '-' || r == '+' || r == '.') && unicode.IsDigit(l.peek(1))):
+		return l.number(pos), nil
+	case isIdentStart(r):
+		return l.ident(pos), nil
+	}
+	return token{}, &Error{pos, fmt.Sprintf("unexpected character %q", r)}
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// ident reads a name, including the dots of a relationship path such as Account.Owner.Name
+func (l *lexer) ident(pos Pos) token {
+	start := l.i
+	for l.i < len(l.src) {
+		r := l.peek(0)
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || (r == '.' && isIdentStart(l.peek(1))) {
+			l.advance()
+			continue
+		}
+		break
+	}
+	return token{tokenIdent, string(l.src[start:l.i]), pos}
+}
+
+// number reads a number, date or date time.  Dates are written as 2006-01-02, and date times
+// as 2006-01-02T15:04:05Z or with an offset such as +01:00.
+func (l *lexer) number(pos Pos) token {
+	start := l.i
+	if r := l.peek(0); r == '-' || r == '+' {
+		l.advance()
+	}
+	digits := func() int {
+		n := 0
+		for unicode.IsDigit(l.peek(0)) {
+			l.advance()
+			n++
+		}
+		return n
+	}
+	if digits() == 4 && l.peek(0) == '-' && unicode.IsDigit(l.peek(1)) && l.src[start] != '-' && l.src[start] != '+' {
+		for l.peek(0) == '-' || unicode.IsDigit(l.peek(0)) {
+			l.advance()
+		}
+		if l.peek(0) != 'T' {
+			return token{tokenDate, string(l.src[start:l.i]), pos}
+		}
+		for {
+			r := l.peek(0)
+			if unicode.IsDigit(r) || r == 'T' || r == 'Z' || r == ':' || r == '.' || r == '+' || r == '-' {
+				l.advance()
+				continue
+			}
+			break
+		}
+		return token{tokenDateTime, string(l.src[start:l.i]), pos}
+	}
+	if l.peek(0) == '.' {
+		l.advance()
+		digits()
+	}
+	if r := l.peek(0); r == 'e' || r == 'E' {
+		l.advance()
+		if r := l.peek(0); r == '-' || r == '+' {
+			l.advance()
+		}
+		digits()
+	}
+	return token{tokenNumber, string(l.src[start:l.i]), pos}
+}
+
+// string reads a quoted string, returning its unescaped value
+func (l *lexer) string(pos Pos) (token, error) {
+	l.advance()
+	var b strings.Builder
+	for {
+		if l.i >= len(l.src) {
+			return token{}, &Error{pos, "unterminated string"}
+		}
+		r := l.advance()
+		switch r {
+		case '\'':
+			return token{tokenString, b.String(), pos}, nil
+		case '\\':
+			if l.i >= len(l.src) {
+				return token{}, &Error{pos, "unterminated string"}
+			}
+			switch e := l.advance(); e {
+			case 'n':
+				b.WriteRune('\n')
+			case 'r':
+				b.WriteRune('\r')
+			case 't':
+				b.WriteRune('\t')
+			case 'b':
+				b.WriteRune('\b')
+			case 'f':
+				b.WriteRune('\f')
+			case '\'', '"', '\\':
+				b.WriteRune(e)
+			case '%', '_':
+				// LIKE wildcards stay escaped
+				b.WriteRune('\\')
+				b.WriteRune(e)
+			default:
+				return token{}, &Error{Pos{l.line, l.col - 2}, fmt.Sprintf("invalid escape sequence \\%c", e)}
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
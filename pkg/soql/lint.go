@@ -0,0 +1,270 @@
+package soql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/darrenparkinson/sfcli/pkg/salesforce"
+)
+
+// Severity is how serious a lint issue is
+type Severity string
+
+// Severities of lint issues.  Errors will make salesforce reject the query, while warnings
+// are for queries that are likely to be slow or return more than was intended.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a query
+type Issue struct {
+	Pos      Pos
+	Severity Severity
+	Rule     string
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", i.Pos, i.Severity, i.Message, i.Rule)
+}
+
+// Describer returns the describe metadata for an object
+type Describer func(object string) (*salesforce.DescribeResponse, error)
+
+// LintOptions configures the checks made by Lint
+type LintOptions struct {
+	// Describe is used to check that objects and fields exist.  Without it, only the syntax
+	// and the shape of the query are checked.
+	Describe Describer
+	// LargeObjects are the objects whose filters are checked for selectivity.  When empty,
+	// filters on every object are checked.
+	LargeObjects []string
+}
+
+// indexedFields are the standard fields salesforce indexes on every object
+var indexedFields = map[string]bool{
+	"id": true, "name": true, "ownerid": true, "createddate": true, "systemmodstamp": true, "recordtypeid": true,
+}
+
+// Lint parses the statement and checks it for problems, returning the issues in the order they
+// appear.  A syntax error is returned as the only issue.
+func Lint(s string, opts LintOptions) []Issue {
+	q, err := Parse(s)
+	if err != nil {
+		if e, ok := err.(*Error); ok {
+			return []Issue{{Pos: e.Pos, Severity: SeverityError, Rule: "syntax", Message: e.Msg}}
+		}
+		return []Issue{{Severity: SeverityError, Rule: "syntax", Message: err.Error()}}
+	}
+	l := &linter{opts: opts}
+	l.query(q, true)
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i].Pos, l.issues[j].Pos
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+	return l.issues
+}
+
+type linter struct {
+	opts   LintOptions
+	issues []Issue
+}
+
+func (l *linter) add(pos Pos, severity Severity, rule, format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{Pos: pos, Severity: severity, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// describe returns the describe for the object, adding an issue if it can't be found
+func (l *linter) describe(object string, pos Pos) *salesforce.DescribeResponse {
+	if l.opts.Describe == nil {
+		return nil
+	}
+	dr, err := l.opts.Describe(object)
+	if err != nil {
+		l.add(pos, SeverityError, "unknown-object", "can't describe %s: %s", object, err)
+		return nil
+	}
+	return dr
+}
+
+func (l *linter) query(q *Query, top bool) {
+	dr := l.describe(q.Object, q.Pos)
+	aliases := make(map[string]bool)
+	aggregate := len(q.GroupBy) > 0
+	for _, f := range q.Fields {
+		if f.Alias != "" {
+			aliases[strings.ToLower(f.Alias)] = true
+		}
+		if f.IsAggregate() {
+			aggregate = true
+		}
+		if f.Function && strings.EqualFold(f.Name, "FIELDS") {
+			l.fieldsFunction(q, f)
+			continue
+		}
+		// the fields of child relationship subqueries are on another object, which we can't
+		// work out from the describe of this one
+		if f.Subquery != nil {
+			continue
+		}
+		l.fields(q, dr, f.Paths(), nil)
+	}
+	for _, f := range q.GroupBy {
+		l.fields(q, dr, f.Paths(), nil)
+	}
+	for _, o := range q.OrderBy {
+		l.fields(q, dr, o.Field.Paths(), aliases)
+	}
+	l.expr(q, dr, q.Where, nil)
+	l.expr(q, dr, q.Having, aliases)
+
+	if !top {
+		return
+	}
+	if q.Where != nil && l.large(q.Object) && !l.selective(q, dr, q.Where) {
+		l.add(q.Pos, SeverityWarning, "non-selective", "the filter on %s doesn't use an indexed field, so it may time out on a large object", q.Object)
+	}
+	if q.Limit == nil && !aggregate {
+		l.add(q.Pos, SeverityWarning, "missing-limit", "there is no LIMIT, so every matching record will be returned")
+	}
+}
+
+// fieldsFunction checks FIELDS(ALL), FIELDS(STANDARD) and FIELDS(CUSTOM)
+func (l *linter) fieldsFunction(q *Query, f *Field) {
+	if len(f.Args) != 1 || f.Args[0].Function {
+		l.add(f.Pos, SeverityError, "syntax", "FIELDS requires ALL, STANDARD or CUSTOM")
+		return
+	}
+	switch strings.ToUpper(f.Args[0].Name) {
+	case "STANDARD":
+	case "ALL", "CUSTOM":
+		if q.Limit == nil || *q.Limit > 200 {
+			l.add(f.Pos, SeverityWarning, "fields-limit", "%s requires LIMIT 200 or less unless it is expanded into a list of fields, as sfcli query and bulk copy do", f)
+		}
+	default:
+		l.add(f.Args[0].Pos, SeverityError, "syntax", "FIELDS requires ALL, STANDARD or CUSTOM, not %s", f.Args[0].Name)
+	}
+}
+
+// fields checks that each field path exists on the object.  Names in aliases are skipped since
+// they refer to aggregate fields in the select list.
+func (l *linter) fields(q *Query, dr *salesforce.DescribeResponse, paths []*Field, aliases map[string]bool) {
+	if dr == nil {
+		return
+	}
+	for _, p := range paths {
+		if aliases[strings.ToLower(p.Name)] {
+			continue
+		}
+		if msg := l.resolve(q, dr, p.Name); msg != "" {
+			l.add(p.Pos, SeverityError, "unknown-field", "%s", msg)
+		}
+	}
+}
+
+// resolve follows a field path from the object, returning a message if a part of it doesn't exist.
+// Paths through polymorphic relationships such as Owner are only checked as far as the relationship.
+func (l *linter) resolve(q *Query, dr *salesforce.DescribeResponse, path string) string {
+	parts := strings.Split(path, ".")
+	if len(parts) > 1 && (strings.EqualFold(parts[0], q.Alias) || strings.EqualFold(parts[0], q.Object)) {
+		parts = parts[1:]
+	}
+	for i, part := range parts {
+		if i == len(parts)-1 {
+			if dr.FieldByName(part) == nil {
+				return fmt.Sprintf("%s has no field %s", dr.Name, part)
+			}
+			return ""
+		}
+		f := dr.FieldByRelationship(part)
+		if f == nil {
+			return fmt.Sprintf("%s has no relationship %s", dr.Name, part)
+		}
+		refs := f.ReferenceTo
+		if len(refs) != 1 {
+			return ""
+		}
+		next, err := l.opts.Describe(refs[0])
+		if err != nil {
+			return ""
+		}
+		dr = next
+	}
+	return ""
+}
+
+func (l *linter) expr(q *Query, dr *salesforce.DescribeResponse, e Expr, aliases map[string]bool) {
+	switch e := e.(type) {
+	case *Logical:
+		l.expr(q, dr, e.Left, aliases)
+		l.expr(q, dr, e.Right, aliases)
+	case *Not:
+		l.expr(q, dr, e.Expr, aliases)
+	case *Comparison:
+		l.fields(q, dr, e.Field.Paths(), aliases)
+		if e.Subquery != nil {
+			l.query(e.Subquery, false)
+		}
+	}
+}
+
+// large reports whether filters on the object should be checked for selectivity
+func (l *linter) large(object string) bool {
+	if len(l.opts.LargeObjects) == 0 {
+		return true
+	}
+	for _, o := range l.opts.LargeObjects {
+		if strings.EqualFold(o, object) {
+			return true
+		}
+	}
+	return false
+}
+
+// selective reports whether the filter narrows the records down using an index.  Conditions joined
+// by AND are selective when any of them is, while those joined by OR must all be selective.
+func (l *linter) selective(q *Query, dr *salesforce.DescribeResponse, e Expr) bool {
+	switch e := e.(type) {
+	case *Logical:
+		if e.Op == "AND" {
+			return l.selective(q, dr, e.Left) || l.selective(q, dr, e.Right)
+		}
+		return l.selective(q, dr, e.Left) && l.selective(q, dr, e.Right)
+	case *Comparison:
+		if e.Field.Function || !l.indexed(q, dr, e.Field.Name) {
+			return false
+		}
+		switch e.Op {
+		case "!=", "NOT IN", "EXCLUDES", "INCLUDES":
+			return false
+		case "LIKE":
+			return e.Value.Kind == Bind || !strings.HasPrefix(e.Value.Text, "%")
+		}
+		return e.Value == nil || e.Value.Kind != Null
+	}
+	// negative filters can't use an index
+	return false
+}
+
+// indexed reports whether the field is one salesforce indexes
+func (l *linter) indexed(q *Query, dr *salesforce.DescribeResponse, name string) bool {
+	if i := strings.Index(name, "."); i > 0 && (strings.EqualFold(name[:i], q.Alias) || strings.EqualFold(name[:i], q.Object)) {
+		name = name[i+1:]
+	}
+	if strings.Contains(name, ".") {
+		return false
+	}
+	if indexedFields[strings.ToLower(name)] {
+		return true
+	}
+	if dr == nil {
+		return false
+	}
+	f := dr.FieldByName(name)
+	if f == nil {
+		return false
+	}
+	return f.ExternalID || f.Unique || f.IDLookup || f.Type == "reference" || f.Type == "id"
+}
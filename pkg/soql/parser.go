@@ -0,0 +1,479 @@
+// Package soql parses SOQL statements into a syntax tree so they can be checked and rewritten
+// without sending them to salesforce.
+package soql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// keywords can't be used as aliases
+var keywords = map[string]bool{
+	"AND": true, "ASC": true, "BY": true, "DESC": true, "EXCLUDES": true, "FALSE": true, "FOR": true,
+	"FROM": true, "GROUP": true, "HAVING": true, "IN": true, "INCLUDES": true, "LIKE": true, "LIMIT": true,
+	"NOT": true, "NULL": true, "NULLS": true, "OFFSET": true, "OR": true, "ORDER": true, "SELECT": true,
+	"TRUE": true, "USING": true, "WHERE": true, "WITH": true,
+}
+
+// dateLiterals are the relative dates that can be used as values, with those taking a number
+// written with N, e.g. LAST_N_DAYS:5 or N_DAYS_AGO:5
+var dateLiterals = map[string]bool{
+	"YESTERDAY": true, "TODAY": true, "TOMORROW": true,
+	"LAST_WEEK": true, "THIS_WEEK": true, "NEXT_WEEK": true,
+	"LAST_MONTH": true, "THIS_MONTH": true, "NEXT_MONTH": true,
+	"LAST_90_DAYS": true, "NEXT_90_DAYS": true, "LAST_N_DAYS": true, "NEXT_N_DAYS": true, "N_DAYS_AGO": true,
+	"LAST_N_WEEKS": true, "NEXT_N_WEEKS": true, "N_WEEKS_AGO": true,
+	"LAST_N_MONTHS": true, "NEXT_N_MONTHS": true, "N_MONTHS_AGO": true,
+	"LAST_QUARTER": true, "THIS_QUARTER": true, "NEXT_QUARTER": true,
+	"LAST_N_QUARTERS": true, "NEXT_N_QUARTERS": true, "N_QUARTERS_AGO": true,
+	"LAST_YEAR": true, "THIS_YEAR": true, "NEXT_YEAR": true,
+	"LAST_N_YEARS": true, "NEXT_N_YEARS": true, "N_YEARS_AGO": true,
+	"LAST_FISCAL_QUARTER": true, "THIS_FISCAL_QUARTER": true, "NEXT_FISCAL_QUARTER": true,
+	"LAST_N_FISCAL_QUARTERS": true, "NEXT_N_FISCAL_QUARTERS": true, "N_FISCAL_QUARTERS_AGO": true,
+	"LAST_FISCAL_YEAR": true, "THIS_FISCAL_YEAR": true, "NEXT_FISCAL_YEAR": true,
+	"LAST_N_FISCAL_YEARS": true, "NEXT_N_FISCAL_YEARS": true, "N_FISCAL_YEARS_AGO": true,
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}\d+(\.\d+)?$`)
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+// Parse parses a SOQL statement.  A syntax error is returned as an *Error with its position.
+func Parse(s string) (*Query, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %s after the end of the query", t)
+	}
+	return q, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the keyword
+func (p *parser) accept(keyword string) bool {
+	if p.peek().is(keyword) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(keywords ...string) error {
+	for _, k := range keywords {
+		if t := p.next(); !t.is(k) {
+			return p.errorf(t, "expected %s, found %s", k, t)
+		}
+	}
+	return nil
+}
+
+func (p *parser) expectKind(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.errorf(t, "expected %s, found %s", what, t)
+	}
+	return t, nil
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return &Error{t.pos, fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) query() (*Query, error) {
+	start := p.peek()
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	q := &Query{Pos: start.pos}
+	for {
+		f, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		q.Fields = append(q.Fields, f)
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	t, err := p.expectKind(tokenIdent, "an object")
+	if err != nil {
+		return nil, err
+	}
+	q.Object = t.text
+	if t := p.peek(); t.kind == tokenIdent && !keywords[strings.ToUpper(t.text)] {
+		q.Alias = p.next().text
+	}
+	if p.accept("USING") {
+		if err := p.expect("SCOPE"); err != nil {
+			return nil, err
+		}
+		t, err := p.expectKind(tokenIdent, "a scope")
+		if err != nil {
+			return nil, err
+		}
+		q.Scope = t.text
+	}
+	if p.accept("WHERE") {
+		if q.Where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("WITH") {
+		t, err := p.expectKind(tokenIdent, "a filter such as SECURITY_ENFORCED")
+		if err != nil {
+			return nil, err
+		}
+		if t.is("DATA") {
+			return nil, p.errorf(t, "WITH DATA CATEGORY is not supported")
+		}
+		q.With = strings.ToUpper(t.text)
+	}
+	if p.accept("GROUP") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			f, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+			q.GroupBy = append(q.GroupBy, f)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.accept("HAVING") {
+		if len(q.GroupBy) == 0 {
+			return nil, p.errorf(p.tokens[p.i-1], "HAVING requires GROUP BY")
+		}
+		if q.Having, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			o, err := p.order()
+			if err != nil {
+				return nil, err
+			}
+			q.OrderBy = append(q.OrderBy, o)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.accept("LIMIT") {
+		if q.Limit, err = p.integer(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("OFFSET") {
+		if q.Offset, err = p.integer(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("FOR") {
+		t := p.next()
+		if !t.is("VIEW") && !t.is("REFERENCE") && !t.is("UPDATE") {
+			return nil, p.errorf(t, "expected VIEW, REFERENCE or UPDATE, found %s", t)
+		}
+		q.For = strings.ToUpper(t.text)
+	}
+	return q, nil
+}
+
+func (p *parser) integer() (*int, error) {
+	t, err := p.expectKind(tokenNumber, "a number")
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(t.text)
+	if err != nil || n < 0 {
+		return nil, p.errorf(t, "expected a whole number, found %s", t)
+	}
+	return &n, nil
+}
+
+func (p *parser) selectItem() (*Field, error) {
+	if t := p.peek(); t.kind == tokenLParen {
+		p.next()
+		sub, err := p.query()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectKind(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return &Field{Subquery: sub, Pos: t.pos}, nil
+	}
+	if t := p.peek(); t.is("TYPEOF") {
+		return nil, p.errorf(t, "TYPEOF is not supported")
+	}
+	f, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokenIdent && !keywords[strings.ToUpper(t.text)] {
+		f.Alias = p.next().text
+	}
+	return f, nil
+}
+
+// field parses a field path or a function call such as COUNT(Id) or convertCurrency(Amount)
+func (p *parser) field() (*Field, error) {
+	t, err := p.expectKind(tokenIdent, "a field")
+	if err != nil {
+		return nil, err
+	}
+	if keywords[strings.ToUpper(t.text)] {
+		return nil, p.errorf(t, "expected a field, found %s", t)
+	}
+	f := &Field{Name: t.text, Pos: t.pos}
+	if p.peek().kind != tokenLParen {
+		return f, nil
+	}
+	p.next()
+	f.Function = true
+	if p.peek().kind == tokenRParen {
+		p.next()
+		return f, nil
+	}
+	for {
+		arg, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		f.Args = append(f.Args, arg)
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expectKind(tokenRParen, ")"); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *parser) order() (*Order, error) {
+	f, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+	o := &Order{Field: f}
+	if p.accept("DESC") {
+		o.Desc = true
+	} else {
+		p.accept("ASC")
+	}
+	if p.accept("NULLS") {
+		t := p.next()
+		if !t.is("FIRST") && !t.is("LAST") {
+			return nil, p.errorf(t, "expected FIRST or LAST, found %s", t)
+		}
+		o.Nulls = strings.ToUpper(t.text)
+	}
+	return o, nil
+}
+
+// expr parses conditions joined by OR, which binds more loosely than AND
+func (p *parser) expr() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.accept("NOT") {
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: e}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectKind(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	f, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+	c := &Comparison{Field: f}
+	t := p.next()
+	switch {
+	case t.kind == tokenOperator:
+		c.Op = t.text
+		if c.Op == "<>" {
+			c.Op = "!="
+		}
+	case t.is("LIKE"):
+		c.Op = "LIKE"
+	case t.is("IN"), t.is("INCLUDES"), t.is("EXCLUDES"):
+		c.Op = strings.ToUpper(t.text)
+		return c, p.list(c)
+	case t.is("NOT"):
+		if err := p.expect("IN"); err != nil {
+			return nil, err
+		}
+		c.Op = "NOT IN"
+		return c, p.list(c)
+	default:
+		return nil, p.errorf(t, "expected a comparison operator, found %s", t)
+	}
+	if c.Value, err = p.value(); err != nil {
+		return nil, err
+	}
+	if c.Op == "LIKE" && c.Value.Kind != String && c.Value.Kind != Bind {
+		return nil, &Error{c.Value.Pos, "LIKE requires a string"}
+	}
+	return c, nil
+}
+
+// list parses the values or semi-join subquery of IN, NOT IN, INCLUDES or EXCLUDES
+func (p *parser) list(c *Comparison) error {
+	if t := p.peek(); t.kind == tokenBind {
+		p.next()
+		c.Value = &Value{Kind: Bind, Text: t.text, Pos: t.pos}
+		return nil
+	}
+	if _, err := p.expectKind(tokenLParen, "("); err != nil {
+		return err
+	}
+	if p.peek().is("SELECT") {
+		if c.Op != "IN" && c.Op != "NOT IN" {
+			return p.errorf(p.peek(), "%s can't use a subquery", c.Op)
+		}
+		sub, err := p.query()
+		if err != nil {
+			return err
+		}
+		c.Subquery = sub
+	} else {
+		for {
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			c.Values = append(c.Values, v)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	_, err := p.expectKind(tokenRParen, ")")
+	return err
+}
+
+func (p *parser) value() (*Value, error) {
+	t := p.next()
+	v := &Value{Text: t.text, Pos: t.pos}
+	switch t.kind {
+	case tokenString:
+		v.Kind = String
+	case tokenNumber:
+		v.Kind = Number
+	case tokenDate:
+		v.Kind = Date
+	case tokenDateTime:
+		v.Kind = DateTime
+	case tokenBind:
+		v.Kind = Bind
+	case tokenIdent:
+		upper := strings.ToUpper(t.text)
+		switch {
+		case upper == "TRUE" || upper == "FALSE":
+			v.Kind, v.Text = Boolean, strings.ToLower(t.text)
+		case upper == "NULL":
+			v.Kind, v.Text = Null, "null"
+		case currencyPattern.MatchString(upper):
+			v.Kind = Currency
+		case !dateLiterals[upper]:
+			return nil, p.errorf(t, "expected a value, found %s", t)
+		default:
+			v.Kind, v.Text = DateLiteral, upper
+			if strings.HasPrefix(upper, "N_") || strings.Contains(upper, "_N_") {
+				if _, err := p.expectKind(tokenColon, ":"); err != nil {
+					return nil, err
+				}
+				n, err := p.expectKind(tokenNumber, "a number")
+				if err != nil {
+					return nil, err
+				}
+				v.Text += ":" + n.text
+			}
+		}
+	default:
+		return nil, p.errorf(t, "expected a value, found %s", t)
+	}
+	return v, nil
+}
@@ -0,0 +1,40 @@
+package soql
+
+import "testing"
+
+func TestParseDateLiterals(t *testing.T) {
+	tests := []struct {
+		where string
+		want  string
+	}{
+		{"CreatedDate = TODAY", "TODAY"},
+		{"CreatedDate = LAST_N_DAYS:5", "LAST_N_DAYS:5"},
+		{"CreatedDate = N_DAYS_AGO:5", "N_DAYS_AGO:5"},
+		{"CreatedDate = n_weeks_ago:3", "N_WEEKS_AGO:3"},
+		{"CloseDate = N_FISCAL_YEARS_AGO:2", "N_FISCAL_YEARS_AGO:2"},
+		{"CloseDate < NEXT_N_FISCAL_QUARTERS:1", "NEXT_N_FISCAL_QUARTERS:1"},
+	}
+	for _, tt := range tests {
+		q, err := Parse("SELECT Id FROM Opportunity WHERE " + tt.where)
+		if err != nil {
+			t.Errorf("%s: %s", tt.where, err)
+			continue
+		}
+		c, ok := q.Where.(*Comparison)
+		if !ok {
+			t.Errorf("%s: expected a comparison, got %T", tt.where, q.Where)
+			continue
+		}
+		if c.Value == nil || c.Value.Kind != DateLiteral || c.Value.Text != tt.want {
+			t.Errorf("%s: got %+v, want date literal %s", tt.where, c.Value, tt.want)
+		}
+	}
+}
+
+func TestParseDateLiteralMissingNumber(t *testing.T) {
+	for _, where := range []string{"CreatedDate = N_DAYS_AGO", "CreatedDate = LAST_N_DAYS"} {
+		if _, err := Parse("SELECT Id FROM Account WHERE " + where); err == nil {
+			t.Errorf("%s: expected an error", where)
+		}
+	}
+}